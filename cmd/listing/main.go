@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"log"
+	"net"
 	"os"
 
 	heroku "github.com/heroku/heroku-go/v3"
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
 	"github.com/shinji62/heroku-asset-listing/pkg/herokuls"
 	"github.com/shinji62/heroku-asset-listing/pkg/output"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
@@ -19,18 +21,127 @@ var (
 		"heroku.token",
 		"(Optional) Heroku Authorizations Token. If token is present, basic auth will be ignored.",
 	).Short('t').Envar("HEROKU_AUTH_TOKEN").String()
+	envConfigVar = cli.Flag("environment-config-var", "config var holding the environment of an app, wins over pipeline stage and name suffix").Envar("ENVIRONMENT_CONFIG_VAR").String()
 
 	cloud         = cli.Command("cloud", "list cloud assets")
-	format        = cloud.Flag("format", "formating output (valid values json,tab,pretty-json default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json")
+	format        = cloud.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
 	dynoUnitPrice = cloud.Flag("heroku.dyno-unit-price", "Price in $ of 1 dyno unit (default 0)").Envar("HEROKU_DYNO_PRICE").Default("0").Int()
+	budgetFile    = cloud.Flag("budget", "yaml file of monthly budgets by organization and cost center, evaluated after the listing").ExistingFile()
+	budgetMapping = cloud.Flag("mapping", "yaml file mapping apps to cost centers, required by cost center budgets").ExistingFile()
+	byEnvironment = cloud.Flag("by-environment", "group apps by environment with a subtotal by environment").Bool()
 
 	ips        = cli.Command("ips", "list Outbouand ips")
 	outputFile = ips.Flag("output", "Output filename").Short('o').Default("ips-listing.yml").String()
+	teamTypes  = ips.Flag("team-type", "Team type to include, can be repeated (valid values enterprise,team default to enterprise)").Default("enterprise").Enums("enterprise", "team")
+	teamNames  = ips.Flag("team", "Only include spaces owned by this team, can be repeated").Strings()
+	spaceNames = ips.Flag("space", "Only include this space, can be repeated").Strings()
+
+	access       = cli.Command("access", "list collaborators, roles and permissions by app")
+	accessFormat = access.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	accessByUser = access.Flag("by-user", "list every app a user can touch instead of collaborators by app").Bool()
+
+	members       = cli.Command("members", "list team members with their 2FA status and pending invitations")
+	membersFormat = members.Flag("format", "formating output (valid values json,tab,pretty-json,csv,junit,sarif default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv", "junit", "sarif")
+	require2FA    = members.Flag("require-2fa", "exit with an error when a non federated member has not enabled 2FA").Bool()
+
+	userAccess       = cli.Command("user-access", "list every team, app, OAuth authorization and SSH key tied to an email")
+	userAccessEmail  = userAccess.Arg("email", "email of the user").Required().String()
+	userAccessFormat = userAccess.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+
+	certs       = cli.Command("certs", "list TLS certificates, ACM status and uncovered custom domains")
+	certsFormat = certs.Flag("format", "formating output (valid values json,tab,pretty-json,csv,junit,sarif default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv", "junit", "sarif")
+	warnDays    = certs.Flag("warn-days", "flag certificates expiring within this number of days").Default("30").Int()
+
+	domains       = cli.Command("domains", "list custom domains and validate their DNS target")
+	domainsFormat = domains.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	resolveDNS    = domains.Flag("resolve", "resolve custom domains to check they point to the heroku DNS target").Bool()
+
+	config        = cli.Command("config", "list config var keys with value fingerprints, values are never printed")
+	configFormat  = config.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	configSalt    = config.Flag("salt", "salt of the value fingerprints (default random, fingerprints are then only comparable within one run)").Envar("CONFIG_FINGERPRINT_SALT").String()
+	configKey     = config.Flag("key", "only list apps defining this config var key").String()
+	configShared  = config.Flag("shared", "list values shared by several apps instead of keys by app").Bool()
+	configSecrets = config.Flag("secrets-only", "with --shared, only consider credential like values").Bool()
+
+	stacks       = cli.Command("stacks", "group apps by stack and highlight deprecated or end of life stacks")
+	stacksFormat = stacks.Flag("format", "formating output (valid values json,tab,pretty-json,csv,junit,sarif default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv", "junit", "sarif")
+	eolFile      = stacks.Flag("eol-file", "yaml file mapping stack name to end of life date (2006-01-02), default to the built-in table").ExistingFile()
+	eolWarnDays  = stacks.Flag("eol-warn-days", "flag stacks reaching end of life within this number of days, they do not fail --fail-on-eol").Default("180").Int()
+	failOnEOL    = stacks.Flag("fail-on-eol", "exit with an error when an app runs on a deprecated or end of life stack").Bool()
+
+	releases          = cli.Command("releases", "report last deploy, deployer, rollbacks and deploy frequency by app")
+	releasesFormat    = releases.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	maxReleases       = releases.Flag("max-releases", "maximum number of releases fetched by app, newest first until one is older than the window, apps reaching it are flagged truncated").Default("1000").Int()
+	releaseWindowDays = releases.Flag("window-days", "window in days used for the release, deploy and rollback counts").Default("30").Int()
+	staleDays         = releases.Flag("stale-days", "flag apps without release for this number of days").Default("365").Int()
+	busyPerDay        = releases.Flag("busy-per-day", "flag apps deploying more than this number of times a day").Default("10").Float64()
+
+	idle          = cli.Command("idle", "score apps by idle signals and list candidates for deletion")
+	idleFormat    = idle.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	idleStaleDays = idle.Flag("stale-days", "a release older than this number of days is an idle signal").Default("365").Int()
+	idleMinScore  = idle.Flag("min-score", "minimum number of idle signals to list an app").Default("3").Int()
+
+	recommend          = cli.Command("recommend", "recommend dyno formation changes with estimated monthly savings")
+	recommendFormat    = recommend.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	recommendRulesFile = recommend.Flag("rules", "yaml file overriding the rightsizing rules and the price catalog").ExistingFile()
+	recommendUnitPrice = recommend.Flag("heroku.dyno-unit-price", "Price in $ of 1 dyno unit, used for sizes missing from the price catalog (default 0)").Envar("HEROKU_DYNO_PRICE").Default("0").Int()
+
+	chargeback          = cli.Command("chargeback", "sum dyno and add-on cost by cost center")
+	chargebackFormat    = chargeback.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	chargebackMapping   = chargeback.Flag("mapping", "yaml file mapping apps to cost centers").Required().ExistingFile()
+	chargebackUnitPrice = chargeback.Flag("heroku.dyno-unit-price", "Price in $ of 1 dyno unit (default 0)").Envar("HEROKU_DYNO_PRICE").Default("0").Int()
+
+	invoices          = cli.Command("invoices", "compare team invoices with the cost computed from the inventory")
+	invoicesFormat    = invoices.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	invoicesLast      = invoices.Flag("last", "number of most recent invoices by team").Default("6").Int()
+	invoicesUnitPrice = invoices.Flag("heroku.dyno-unit-price", "Price in $ of 1 dyno unit (default 0)").Envar("HEROKU_DYNO_PRICE").Default("0").Int()
+
+	pipelines          = cli.Command("pipelines", "list pipelines with their apps and dyno cost by stage")
+	pipelinesFormat    = pipelines.Flag("format", "formating output (valid values json,tab,pretty-json,csv,dot default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv", "dot")
+	pipelinesUnitPrice = pipelines.Flag("heroku.dyno-unit-price", "Price in $ of 1 dyno unit (default 0)").Envar("HEROKU_DYNO_PRICE").Default("0").Int()
+
+	addons       = cli.Command("addons", "list every add-on of the account grouped by service with their cost")
+	addonsFormat = addons.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+
+	allowlist          = cli.Command("addon-allowlist", "compare team add-on allowlists with the add-ons in use and the desired allowlist")
+	allowlistFormat    = allowlist.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	allowlistDesired   = allowlist.Flag("desired", "yaml file of the add-on services teams should allow").ExistingFile()
+	failOnNonCompliant = allowlist.Flag("fail-on-violation", "exit with an error when an add-on is not compliant or a team has no allowlist").Bool()
+
+	policy       = cli.Command("policy", "evaluate a yaml policy file against the inventory, exit with an error when a rule fails")
+	policyFormat = policy.Flag("format", "formating output (valid values json,tab,pretty-json,csv,junit,sarif default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv", "junit", "sarif")
+	policyRules  = policy.Flag("rules", "yaml policy file").Required().ExistingFile()
+	policyJUnit  = policy.Flag("junit", "also write a JUnit XML report to this file").String()
+
+	drains             = cli.Command("drains", "list log drains with redacted credentials, apps without drain and unknown destinations")
+	drainsFormat       = drains.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	drainAllowedHosts  = drains.Flag("allowed-host", "host drains may ship logs to, wildcards like *.example.com are accepted, can be repeated").Strings()
+	drainsEnvironments = drains.Flag("environment", "only list apps of this environment, can be repeated").Strings()
+
+	webhooks            = cli.Command("webhooks", "list app webhooks with their recent delivery status")
+	webhooksFormat      = webhooks.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	webhookAllowedHosts = webhooks.Flag("allowed-host", "host webhooks may target, wildcards like *.example.com are accepted, can be repeated").Strings()
+	webhookFailures     = webhooks.Flag("failed-deliveries", "flag webhooks whose last deliveries failed this number of times in a row").Default("3").Int()
+	webhookDeliveries   = webhooks.Flag("max-deliveries", "number of deliveries fetched by request, pages are fetched until every webhook has --failed-deliveries deliveries").Default("100").Int()
+
+	features       = cli.Command("features", "list enabled app and team features as an apps by features matrix")
+	featuresFormat = features.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	featureNames   = features.Flag("feature", "only report this feature, can be repeated").Strings()
+
+	builds             = cli.Command("builds", "list buildpacks, last build and slug size by app")
+	buildsFormat       = builds.Flag("format", "formating output (valid values json,tab,pretty-json,csv default to tab)").Envar("OUTPUT_FORMAT").Default("tab").Enum("json", "tab", "pretty-json", "csv")
+	outdatedBuildpacks = builds.Flag("outdated-buildpack", "flag buildpacks whose name or url start with this prefix, can be repeated").Strings()
+	slugWarnMB         = builds.Flag("slug-warn-mb", "flag slugs from this size in MB, heroku limit is 500").Default("400").Int()
 )
 
 const (
-	ExitCodeOk    = 0
-	ExitCodeError = 1 + iota
+	ExitCodeOk           = 0
+	ExitCodeError        = 1 + iota
+	ExitCode2FAViolation = 1 + iota
+	ExitCodeStackEOL     = 1 + iota
+	ExitCodeBudget       = 1 + iota
+	ExitCodeAllowlist    = 1 + iota
+	ExitCodePolicy       = 1 + iota
 )
 
 var (
@@ -64,23 +175,35 @@ func main() {
 		dynoSize, err := hls.GetDynoSizeInformation()
 		if err != nil {
 			fmt.Println(err)
+			// dyno costs are 0 without the dyno sizes, budgets cannot be checked
+			if *budgetFile != "" {
+				os.Exit(ExitCodeError)
+			}
 		}
-		var out output.Output
-		switch *format {
-		case "json":
-			out = output.NewJsonWriter(os.Stdout, false)
-		case "pretty-json":
-			out = output.NewJsonWriter(os.Stdout, true)
-		case "tab":
-			out = output.NewTabWriter(os.Stdout)
-		default:
-			fmt.Println("Only json,tab,pretty-json are accepted")
-			os.Exit(ExitCodeError)
+		pricing := herokuls.DynoPricing{
+			Units:     dynoSize,
+			UnitPrice: *dynoUnitPrice,
+		}
+		if *byEnvironment {
+			classifyEnvironments(hls, herokuOrgs)
+			newOutput(*format).RenderAppsByEnvironment(herokuls.GroupByEnvironment(herokuOrgs, dynoSize, pricing), dynoSize, *dynoUnitPrice)
+		} else {
+			newOutput(*format).RenderApps(herokuOrgs, dynoSize, *dynoUnitPrice)
 		}
 
-		out.RenderApps(herokuOrgs, dynoSize, *dynoUnitPrice)
+		if *budgetFile != "" {
+			checkBudgets(hls, herokuOrgs, pricing)
+		}
 	case ips.FullCommand():
-		ipList := hls.GetIPList("heroku-ips-listing", "ips from heroku spaces")
+		ipList, err := hls.GetIPList("heroku-ips-listing", "ips from heroku spaces", herokuls.IPListFilter{
+			TeamTypes: *teamTypes,
+			Teams:     *teamNames,
+			Spaces:    *spaceNames,
+		})
+		if err != nil || ipList == nil {
+			fmt.Println(fmt.Sprintf("Error collecting ips from heroku spaces: %v", err))
+			os.Exit(ExitCodeError)
+		}
 
 		f, err := os.Create(*outputFile)
 		if err != nil {
@@ -91,6 +214,573 @@ func main() {
 		ipList.Type = "heroku"
 		ipList.Yamlize(f)
 		fmt.Println(fmt.Sprintf("Success! Created file: %s", *outputFile))
+	case access.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		appsAccess, err := hls.ListAccessByApp(herokuOrgs)
+		if err != nil {
+			fmt.Println(err)
+		}
+		out := newOutput(*accessFormat)
+		if *accessByUser {
+			out.RenderUserAccess(herokuls.AccessByUser(appsAccess))
+		} else {
+			out.RenderAccess(appsAccess)
+		}
+	case members.FullCommand():
+		report, err := hls.ListTeamMembers()
+		if err != nil {
+			fmt.Println(err)
+			// a partial member list cannot prove every member has 2FA
+			if *require2FA {
+				os.Exit(ExitCodeError)
+			}
+		}
+		if isFindingsFormat(*membersFormat) {
+			renderFindings(*membersFormat, report.Findings())
+		} else {
+			newOutput(*membersFormat).RenderMembers(report)
+		}
+
+		if without2FA := report.MembersWithout2FA(); *require2FA && len(without2FA) > 0 {
+			fmt.Fprintf(os.Stderr, "%d member(s) without 2FA\n", len(without2FA))
+			os.Exit(ExitCode2FAViolation)
+		}
+	case userAccess.FullCommand():
+		identity, err := hls.ListIdentityAccess(*userAccessEmail)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+		newOutput(*userAccessFormat).RenderIdentityAccess(identity)
+	case certs.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		appsCerts, err := hls.ListCertificates(herokuOrgs, *warnDays)
+		if err != nil {
+			fmt.Println(err)
+		}
+		if isFindingsFormat(*certsFormat) {
+			renderFindings(*certsFormat, herokuls.CertificateFindings(appsCerts))
+		} else {
+			newOutput(*certsFormat).RenderCertificates(appsCerts)
+		}
+		if expiring := herokuls.ExpiringCertificates(appsCerts); expiring > 0 {
+			fmt.Fprintf(os.Stderr, "%d certificate(s) expire within %d days\n", expiring, *warnDays)
+		}
+	case domains.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		var resolver herokuls.Resolver
+		if *resolveDNS {
+			resolver = net.DefaultResolver
+		}
+		appDomains, err := hls.ListDomains(herokuOrgs, resolver)
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*domainsFormat).RenderDomains(appDomains)
+		if misconfigured := herokuls.MisconfiguredDomains(appDomains); misconfigured > 0 {
+			fmt.Fprintf(os.Stderr, "%d domain(s) dangling or misconfigured\n", misconfigured)
+		}
+		if unresolved := herokuls.UnresolvedDomains(appDomains); unresolved > 0 {
+			fmt.Fprintf(os.Stderr, "%d domain(s) could not be resolved\n", unresolved)
+		}
+	case config.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		salt := *configSalt
+		if salt == "" {
+			if salt, err = herokuls.RandomSalt(); err != nil {
+				fmt.Println(err)
+				os.Exit(ExitCodeError)
+			}
+		}
+		appsConfig, err := hls.ListConfigVars(herokuOrgs, salt)
+		if err != nil {
+			fmt.Println(err)
+		}
+		if *configKey != "" {
+			appsConfig = herokuls.FilterConfigByKey(appsConfig, *configKey)
+		}
+		out := newOutput(*configFormat)
+		if *configShared {
+			out.RenderSharedConfig(herokuls.SharedConfigValues(appsConfig, *configSecrets))
+		} else {
+			out.RenderConfig(appsConfig)
+		}
+	case stacks.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		eol := herokuls.DefaultStackEOL
+		if *eolFile != "" {
+			f, err := os.Open(*eolFile)
+			if err != nil {
+				fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+				os.Exit(ExitCodeError)
+			}
+			eol, err = herokuls.LoadStackEOL(f)
+			f.Close()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(ExitCodeError)
+			}
+		}
+		report, err := hls.GetStackReport(herokuOrgs, eol, *eolWarnDays)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+		if isFindingsFormat(*stacksFormat) {
+			renderFindings(*stacksFormat, report.Findings())
+		} else {
+			newOutput(*stacksFormat).RenderStacks(report)
+		}
+
+		// the gate counts the error findings so JUnit and SARIF reports fail on the same apps
+		if unsupported := report.Findings().Errors(); *failOnEOL && unsupported > 0 {
+			fmt.Fprintf(os.Stderr, "%d app(s) on deprecated or end of life stacks\n", unsupported)
+			os.Exit(ExitCodeStackEOL)
+		}
+	case releases.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		activities, err := hls.ListReleaseActivity(herokuOrgs, herokuls.ReleaseActivityOptions{
+			MaxReleases: *maxReleases,
+			WindowDays:  *releaseWindowDays,
+			StaleDays:   *staleDays,
+			BusyPerDay:  *busyPerDay,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*releasesFormat).RenderReleases(activities)
+	case idle.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		idleApps, err := hls.ListIdleApps(herokuOrgs, herokuls.IdleOptions{
+			StaleDays: *idleStaleDays,
+			MinScore:  *idleMinScore,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*idleFormat).RenderIdleApps(idleApps)
+	case recommend.FullCommand():
+		rules := herokuls.DefaultRecommendRules()
+		if *recommendRulesFile != "" {
+			f, err := os.Open(*recommendRulesFile)
+			if err != nil {
+				fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+				os.Exit(ExitCodeError)
+			}
+			rules, err = herokuls.LoadRecommendRules(f)
+			f.Close()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(ExitCodeError)
+			}
+		}
+
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		classifyEnvironments(hls, herokuOrgs)
+		dynoSize, err := hls.GetDynoSizeInformation()
+		if err != nil {
+			fmt.Println(err)
+		}
+		if *recommendUnitPrice == 0 && len(rules.Prices) == 0 {
+			fmt.Fprintln(os.Stderr, "no dyno unit price (--heroku.dyno-unit-price) nor price catalog (--rules), savings are unknown")
+		}
+		recommendations, err := hls.ListRecommendations(herokuOrgs, herokuls.DynoPricing{
+			Units:     dynoSize,
+			UnitPrice: *recommendUnitPrice,
+			Prices:    rules.Prices,
+		}, rules)
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*recommendFormat).RenderRecommendations(recommendations)
+	case chargeback.FullCommand():
+		f, err := os.Open(*chargebackMapping)
+		if err != nil {
+			fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+			os.Exit(ExitCodeError)
+		}
+		mapping, err := herokuls.LoadCostCenterMapping(f)
+		f.Close()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		dynoSize, err := hls.GetDynoSizeInformation()
+		if err != nil {
+			fmt.Println(err)
+		}
+		costs, err := hls.Chargeback(herokuOrgs, mapping, herokuls.DynoPricing{
+			Units:     dynoSize,
+			UnitPrice: *chargebackUnitPrice,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*chargebackFormat).RenderChargeback(costs)
+	case invoices.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		dynoSize, err := hls.GetDynoSizeInformation()
+		if err != nil {
+			fmt.Println(err)
+		}
+		teamsInvoices, err := hls.ListInvoices(herokuOrgs, herokuls.DynoPricing{
+			Units:     dynoSize,
+			UnitPrice: *invoicesUnitPrice,
+		}, *invoicesLast)
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*invoicesFormat).RenderInvoices(teamsInvoices)
+	case pipelines.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		dynoSize, err := hls.GetDynoSizeInformation()
+		if err != nil {
+			fmt.Println(err)
+		}
+		topologies, err := hls.ListPipelines(herokuOrgs, herokuls.DynoPricing{
+			Units:     dynoSize,
+			UnitPrice: *pipelinesUnitPrice,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		if *pipelinesFormat == "dot" {
+			output.NewDotWriter(os.Stdout).RenderPipelines(topologies)
+		} else {
+			newOutput(*pipelinesFormat).RenderPipelines(topologies)
+		}
+	case addons.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		inventory, err := hls.ListAddOnInventory(herokuOrgs)
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*addonsFormat).RenderAddOns(inventory)
+		if flagged := inventory.FlaggedAddOns(); len(flagged) > 0 {
+			fmt.Fprintf(os.Stderr, "%d add-on(s) deprovisioned, provisioning or billed to an app without dynos\n", len(flagged))
+		}
+	case allowlist.FullCommand():
+		var desired herokuls.DesiredAllowlist
+		if *allowlistDesired != "" {
+			f, err := os.Open(*allowlistDesired)
+			if err != nil {
+				fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+				os.Exit(ExitCodeError)
+			}
+			desired, err = herokuls.LoadDesiredAllowlist(f)
+			f.Close()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(ExitCodeError)
+			}
+		}
+
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		teamsAllowlist, err := hls.CheckAddOnAllowlists(herokuOrgs, desired)
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*allowlistFormat).RenderAllowlists(teamsAllowlist)
+		// add-ons of a team whose allowlist could not be read were not checked
+		if err != nil && *failOnNonCompliant {
+			os.Exit(ExitCodeError)
+		}
+
+		nonCompliant, withoutAllowlist := herokuls.AllowlistViolations(teamsAllowlist)
+		if nonCompliant > 0 || withoutAllowlist > 0 {
+			fmt.Fprintf(os.Stderr, "%d non compliant add-on(s), %d team(s) without allowlist\n", nonCompliant, withoutAllowlist)
+			if *failOnNonCompliant {
+				os.Exit(ExitCodeAllowlist)
+			}
+		}
+	case drains.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		classifyEnvironments(hls, herokuOrgs)
+		if len(*drainsEnvironments) > 0 {
+			herokuOrgs = herokuls.FilterByEnvironment(herokuOrgs, *drainsEnvironments)
+		}
+		appsDrains, err := hls.ListDrains(herokuOrgs, herokuls.DrainOptions{
+			AllowedHosts: *drainAllowedHosts,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*drainsFormat).RenderDrains(appsDrains)
+		if undrained := herokuls.UndrainedApps(appsDrains); len(undrained) > 0 {
+			fmt.Fprintf(os.Stderr, "%d app(s) without log drain\n", len(undrained))
+		}
+	case webhooks.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		appsWebhooks, err := hls.ListWebhooks(herokuOrgs, herokuls.WebhookOptions{
+			AllowedHosts:     *webhookAllowedHosts,
+			FailedDeliveries: *webhookFailures,
+			MaxDeliveries:    *webhookDeliveries,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*webhooksFormat).RenderWebhooks(appsWebhooks)
+		if flagged := herokuls.FlaggedWebhooks(appsWebhooks); len(flagged) > 0 {
+			fmt.Fprintf(os.Stderr, "%d webhook(s) failing or targeting an unknown host\n", len(flagged))
+		}
+	case features.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		classifyEnvironments(hls, herokuOrgs)
+		report, err := hls.ListFeatures(herokuOrgs, *featureNames)
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*featuresFormat).RenderFeatures(report)
+	case builds.FullCommand():
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		appsBuilds, err := hls.ListBuilds(herokuOrgs, herokuls.BuildOptions{
+			OutdatedBuildpacks: *outdatedBuildpacks,
+			SlugWarnSize:       *slugWarnMB * 1024 * 1024,
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+		newOutput(*buildsFormat).RenderBuilds(appsBuilds)
+		if flagged := herokuls.FlaggedBuilds(appsBuilds); len(flagged) > 0 {
+			fmt.Fprintf(os.Stderr, "%d app(s) with outdated buildpacks, failing builds or large slugs\n", len(flagged))
+		}
+	case policy.FullCommand():
+		f, err := os.Open(*policyRules)
+		if err != nil {
+			fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+			os.Exit(ExitCodeError)
+		}
+		policyConfig, err := herokuls.LoadPolicyConfig(f)
+		f.Close()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		herokuOrgs, err := hls.ListAllAppsByOrganisation()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+
+		// environment selectors of misclassified apps would let rules pass
+		if err := classifyEnvironments(hls, herokuOrgs); err != nil {
+			os.Exit(ExitCodeError)
+		}
+		if policyConfig.UsesFeatures() {
+			if err := hls.LoadAppFeatures(herokuOrgs); err != nil {
+				fmt.Println(err)
+			}
+		}
+		results := herokuls.EvaluatePolicy(herokuOrgs, policyConfig)
+		if isFindingsFormat(*policyFormat) {
+			renderFindings(*policyFormat, herokuls.PolicyFindings(results))
+		} else {
+			newOutput(*policyFormat).RenderPolicy(results)
+		}
+		if *policyJUnit != "" {
+			report, err := os.Create(*policyJUnit)
+			if err != nil {
+				fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+				os.Exit(ExitCodeError)
+			}
+			output.NewJUnitWriter(report).RenderFindings(herokuls.PolicyFindings(results))
+			report.Close()
+		}
+
+		for _, result := range herokuls.UnmatchedPolicies(results) {
+			fmt.Fprintf(os.Stderr, "policy rule %s matches no app, check its selector\n", result.Rule)
+		}
+		if failed := herokuls.FailedPolicies(results); len(failed) > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d policy rule(s) failed\n", len(failed), len(results))
+			os.Exit(ExitCodePolicy)
+		}
+	}
+
+}
+
+// classifyEnvironments set the environment of every app with the default classifier
+// apps are classified with the remaining rules when pipelines or config vars cannot be listed, the error is printed and returned
+func classifyEnvironments(hls *herokuls.HerokuListing, herokuOrgs []herokuls.HerokuOrganization) error {
+	classifier := herokuls.DefaultEnvironmentClassifier()
+	classifier.ConfigVar = *envConfigVar
+	err := hls.ClassifyEnvironments(herokuOrgs, classifier)
+	if err != nil {
+		fmt.Println(err)
 	}
+	return err
+}
 
+// checkBudgets report budgets on stderr and exit with ExitCodeBudget when one is exceeded or its target is not found
+func checkBudgets(hls *herokuls.HerokuListing, herokuOrgs []herokuls.HerokuOrganization, pricing herokuls.DynoPricing) {
+	f, err := os.Open(*budgetFile)
+	if err != nil {
+		fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+		os.Exit(ExitCodeError)
+	}
+	budgets, err := herokuls.LoadBudgetConfig(f)
+	f.Close()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(ExitCodeError)
+	}
+
+	var costCenterCosts []herokuls.CostCenterCost
+	if len(budgets.CostCenters) > 0 {
+		if *budgetMapping == "" {
+			fmt.Println("--mapping is required by cost center budgets")
+			os.Exit(ExitCodeError)
+		}
+		f, err := os.Open(*budgetMapping)
+		if err != nil {
+			fmt.Println(fmt.Sprintf("Error opening file: %v", err))
+			os.Exit(ExitCodeError)
+		}
+		mapping, err := herokuls.LoadCostCenterMapping(f)
+		f.Close()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+		// incomplete cost center actuals would let an exceeded budget pass
+		if costCenterCosts, err = hls.Chargeback(herokuOrgs, mapping, pricing); err != nil {
+			fmt.Println(err)
+			os.Exit(ExitCodeError)
+		}
+	}
+
+	statuses := herokuls.EvaluateBudgets(budgets, herokuls.OrganizationCosts(herokuOrgs, pricing), costCenterCosts)
+	output.NewTabWriter(os.Stderr).RenderBudgets(statuses)
+	exceeded := herokuls.ExceededBudgets(statuses)
+	for _, status := range exceeded {
+		fmt.Fprintf(os.Stderr, "Budget exceeded for %s %s: %.2f$ of %.2f$ (%.1f%%)\n", status.Scope, status.Name, status.Actual, status.Budget, status.PercentConsumed)
+	}
+	// a budget on a misspelled or missing target would never be exceeded
+	unknown := herokuls.UnknownBudgets(statuses)
+	for _, status := range unknown {
+		fmt.Fprintf(os.Stderr, "Budget target not found for %s %s\n", status.Scope, status.Name)
+	}
+	if len(exceeded) > 0 || len(unknown) > 0 {
+		os.Exit(ExitCodeBudget)
+	}
+}
+
+// isFindingsFormat return true for the formats of audit findings
+func isFindingsFormat(format string) bool {
+	return format == "junit" || format == "sarif"
+}
+
+// renderFindings write audit findings on stdout as JUnit XML or SARIF
+func renderFindings(format string, report findings.Report) {
+	switch format {
+	case "junit":
+		output.NewJUnitWriter(os.Stdout).RenderFindings(report)
+	case "sarif":
+		output.NewSarifWriter(os.Stdout, version).RenderFindings(report)
+	}
+}
+
+// newOutput writer on stdout for the requested format
+func newOutput(format string) output.Output {
+	switch format {
+	case "json":
+		return output.NewJsonWriter(os.Stdout, false)
+	case "pretty-json":
+		return output.NewJsonWriter(os.Stdout, true)
+	case "tab":
+		return output.NewTabWriter(os.Stdout)
+	case "csv":
+		return output.NewCsvWriter(os.Stdout)
+	default:
+		fmt.Println("Only json,tab,pretty-json,csv are accepted")
+		os.Exit(ExitCodeError)
+	}
+	return nil
 }
@@ -0,0 +1,81 @@
+package findings
+
+const (
+	// LevelError finding which fails the audit
+	LevelError = "error"
+	// LevelWarning finding worth a look which does not fail the audit
+	LevelWarning = "warning"
+)
+
+//Report Findings of one audit and every rule it checked
+//Rules without finding passed
+type Report struct {
+	Audit    string    `json:"audit"`
+	Rules    []Rule    `json:"rules"`
+	Findings []Finding `json:"findings"`
+}
+
+//Rule Check of an audit
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+//Finding Resource failing a rule
+//Target is the audited resource, organization/app for an app or the email of a user
+type Finding struct {
+	RuleID  string `json:"rule_id"`
+	Level   string `json:"level"`
+	Target  string `json:"target"`
+	Message string `json:"message"`
+}
+
+//NewReport Report of an audit with its rules
+func NewReport(audit string, rules ...Rule) Report {
+	return Report{
+		Audit: audit,
+		Rules: rules,
+	}
+}
+
+//AddRule Add a rule if it is not already checked by the report
+func (r *Report) AddRule(id, description string) {
+	for _, rule := range r.Rules {
+		if rule.ID == id {
+			return
+		}
+	}
+	r.Rules = append(r.Rules, Rule{ID: id, Description: description})
+}
+
+//Add Add a finding
+func (r *Report) Add(ruleID, level, target, message string) {
+	r.Findings = append(r.Findings, Finding{
+		RuleID:  ruleID,
+		Level:   level,
+		Target:  target,
+		Message: message,
+	})
+}
+
+//ByRule Findings of a rule
+func (r Report) ByRule(ruleID string) []Finding {
+	var findings []Finding
+	for _, finding := range r.Findings {
+		if finding.RuleID == ruleID {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+//Errors Number of findings failing the audit
+func (r Report) Errors() int {
+	var total int
+	for _, finding := range r.Findings {
+		if finding.Level == LevelError {
+			total++
+		}
+	}
+	return total
+}
@@ -0,0 +1,159 @@
+package herokuls
+
+import (
+	"sort"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// RoleAdmin collaborator role which is granted every app permission
+	RoleAdmin = "admin"
+)
+
+//AppAccess Collaborators of an application
+type AppAccess struct {
+	App           string            `json:"application"`
+	Organization  string            `json:"organization"`
+	Collaborators []AppCollaborator `json:"collaborators"`
+}
+
+//AppCollaborator User with his role and permissions on an application
+type AppCollaborator struct {
+	Email       string   `json:"email"`
+	Role        string   `json:"role"`
+	Federated   bool     `json:"federated"`
+	Permissions []string `json:"permissions"`
+}
+
+//UserAccess Every application a user can touch
+type UserAccess struct {
+	Email string          `json:"email"`
+	Apps  []UserAppAccess `json:"applications"`
+}
+
+//UserAppAccess Role and permissions of a user on one application
+type UserAppAccess struct {
+	App          string   `json:"application"`
+	Organization string   `json:"organization"`
+	Role         string   `json:"role"`
+	Permissions  []string `json:"permissions"`
+}
+
+//ListAccessByApp Collect collaborators for every application of the organizations
+func (hls *HerokuListing) ListAccessByApp(herokuOrgs []HerokuOrganization) ([]AppAccess, error) {
+	allPermissions, err := hls.getTeamAppPermissions()
+	if err != nil {
+		return []AppAccess{}, err
+	}
+
+	var appsAccess []AppAccess
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				rl.Take()
+				collaborators, err := hls.getCollaboratorsbyApp(app, allPermissions)
+				if err != nil {
+					errChannel <- err
+				}
+				mutex.Lock()
+				appsAccess = append(appsAccess, AppAccess{
+					App:           app.Name,
+					Organization:  appOrganization(app),
+					Collaborators: collaborators,
+				})
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appsAccess, func(i, j int) bool {
+		if appsAccess[i].Organization != appsAccess[j].Organization {
+			return appsAccess[i].Organization < appsAccess[j].Organization
+		}
+		return appsAccess[i].App < appsAccess[j].App
+	})
+	return appsAccess, <-errChannel
+}
+
+//getTeamAppPermissions Name of every permission available to team apps
+func (hls *HerokuListing) getTeamAppPermissions() ([]string, error) {
+	permissionArr, err := hls.Cli.TeamAppPermissionList(hls.ctx, &heroku.ListRange{Field: "name"})
+	var permissions []string
+	if err != nil {
+		return permissions, err
+	}
+	for _, permission := range permissionArr {
+		permissions = append(permissions, permission.Name)
+	}
+	return permissions, nil
+}
+
+//getCollaboratorsbyApp List collaborators of an application
+//Admins are granted every permission even when the API does not list them
+func (hls *HerokuListing) getCollaboratorsbyApp(app heroku.OrganizationApp, allPermissions []string) ([]AppCollaborator, error) {
+	collaboratorArr, err := hls.Cli.CollaboratorList(hls.ctx, app.ID, &heroku.ListRange{Field: "email"})
+	var collaborators []AppCollaborator
+	if err != nil {
+		return collaborators, err
+	}
+	for _, collaborator := range collaboratorArr {
+		role := stringValue(collaborator.Role)
+		var permissions []string
+		for _, permission := range collaborator.Permissions {
+			permissions = append(permissions, permission.Name)
+		}
+		if role == RoleAdmin && len(permissions) == 0 {
+			permissions = allPermissions
+		}
+		collaborators = append(collaborators, AppCollaborator{
+			Email:       collaborator.User.Email,
+			Role:        role,
+			Federated:   collaborator.User.Federated,
+			Permissions: permissions,
+		})
+	}
+	sort.Slice(collaborators, func(i, j int) bool {
+		return collaborators[i].Email < collaborators[j].Email
+	})
+	return collaborators, nil
+}
+
+//AccessByUser Pivot the application access list to a per user view
+func AccessByUser(appsAccess []AppAccess) []UserAccess {
+	usersApps := make(map[string][]UserAppAccess)
+	for _, appAccess := range appsAccess {
+		for _, collaborator := range appAccess.Collaborators {
+			usersApps[collaborator.Email] = append(usersApps[collaborator.Email], UserAppAccess{
+				App:          appAccess.App,
+				Organization: appAccess.Organization,
+				Role:         collaborator.Role,
+				Permissions:  collaborator.Permissions,
+			})
+		}
+	}
+
+	var usersAccess []UserAccess
+	for email, apps := range usersApps {
+		usersAccess = append(usersAccess, UserAccess{
+			Email: email,
+			Apps:  apps,
+		})
+	}
+	sort.Slice(usersAccess, func(i, j int) bool {
+		return usersAccess[i].Email < usersAccess[j].Email
+	})
+	return usersAccess
+}
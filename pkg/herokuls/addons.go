@@ -0,0 +1,217 @@
+package herokuls
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// AddOnStateDeprovisioned add-on is deprovisioned but still listed
+	AddOnStateDeprovisioned = "deprovisioned"
+	// AddOnStateProvisioning add-on provisioning is not finished
+	AddOnStateProvisioning = "provisioning"
+
+	// AddOnFlagDeprovisioned add-on is in the deprovisioned state
+	AddOnFlagDeprovisioned = "deprovisioned"
+	// AddOnFlagProvisioning add-on is stuck in the provisioning state
+	AddOnFlagProvisioning = "provisioning"
+	// AddOnFlagBillingAppNoDynos the billing app of the add-on has no dyno running
+	AddOnFlagBillingAppNoDynos = "billing-app-without-dynos"
+)
+
+//AddOnInventory Every add-on of the account and their usage by service
+type AddOnInventory struct {
+	Teams    []AddOnTeam         `json:"teams"`
+	Services []AddOnServiceUsage `json:"services"`
+	AddOns   []InventoryAddOn    `json:"addons"`
+}
+
+//AddOnTeam Number of add-ons listed for a team, Error is set when they could not be listed
+type AddOnTeam struct {
+	Team   string `json:"team"`
+	AddOns int    `json:"addons"`
+	Error  string `json:"error,omitempty"`
+}
+
+//AddOnServiceUsage Number of add-ons and monthly cost in $ of an add-on service
+type AddOnServiceUsage struct {
+	Service     string  `json:"service"`
+	Total       int     `json:"total"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+//InventoryAddOn Add-on with its billing app and the apps it is attached to
+//Region is the region of the billing app, empty when the billing app is outside the listing
+type InventoryAddOn struct {
+	Name         string    `json:"name"`
+	Service      string    `json:"service"`
+	Plan         string    `json:"plan"`
+	State        string    `json:"state"`
+	Organization string    `json:"organization"`
+	BillingApp   string    `json:"billing_application"`
+	AttachedApps []string  `json:"attached_applications"`
+	Region       string    `json:"region"`
+	CreatedAt    time.Time `json:"created_at"`
+	MonthlyCost  float64   `json:"monthly_cost"`
+	Flags        []string  `json:"flags"`
+}
+
+//ListAddOnInventory List every add-on used by the apps of every organization
+//AddOnListByTeam is used first, OrganizationAddOnListForOrganization for legacy organizations
+//A team whose add-ons cannot be listed is kept with its Error and the error is returned
+func (hls *HerokuListing) ListAddOnInventory(herokuOrgs []HerokuOrganization) (AddOnInventory, error) {
+	addOnsByOrg := make(map[string][]heroku.AddOn, len(herokuOrgs))
+	errorsByOrg := make(map[string]error)
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, len(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		wg.Add(1)
+		go func(org HerokuOrganization) {
+			defer wg.Done()
+			rl.Take()
+			addOns, err := hls.getAddOnsbyTeam(org.Name())
+			if err != nil {
+				errChannel <- err
+				mutex.Lock()
+				errorsByOrg[org.Name()] = err
+				mutex.Unlock()
+				return
+			}
+			mutex.Lock()
+			addOnsByOrg[org.Name()] = addOns
+			mutex.Unlock()
+		}(org)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	return BuildAddOnInventory(herokuOrgs, addOnsByOrg, errorsByOrg), <-errChannel
+}
+
+//getAddOnsbyTeam Add-ons of a team, converted from the organization add-ons when the team endpoint fails
+func (hls *HerokuListing) getAddOnsbyTeam(team string) ([]heroku.AddOn, error) {
+	addOnArr, err := hls.Cli.AddOnListByTeam(hls.ctx, team, &heroku.ListRange{Field: "name"})
+	if err == nil {
+		return []heroku.AddOn(addOnArr), nil
+	}
+	orgAddOns, orgErr := hls.Cli.OrganizationAddOnListForOrganization(hls.ctx, team, &heroku.ListRange{Field: "name"})
+	if orgErr != nil {
+		return nil, err
+	}
+	var addOns []heroku.AddOn
+	for _, addOn := range orgAddOns {
+		addOns = append(addOns, heroku.AddOn(addOn))
+	}
+	return addOns, nil
+}
+
+//BuildAddOnInventory Merge the add-ons of every organization with the apps of the listing
+//An add-on used by several organizations is only listed once, every organization is listed in Teams
+func BuildAddOnInventory(herokuOrgs []HerokuOrganization, addOnsByOrg map[string][]heroku.AddOn, errorsByOrg map[string]error) AddOnInventory {
+	var inventory AddOnInventory
+	apps := make(map[string]HerokuApp)
+	attachedApps := make(map[string][]string)
+	for _, org := range herokuOrgs {
+		team := AddOnTeam{Team: org.Name(), AddOns: len(addOnsByOrg[org.Name()])}
+		if err, ok := errorsByOrg[org.Name()]; ok {
+			team.Error = err.Error()
+		}
+		inventory.Teams = append(inventory.Teams, team)
+		for _, app := range org.Apps {
+			apps[app.App.Name] = app
+			for _, addOn := range app.AddOns {
+				attachedApps[addOn.Name] = append(attachedApps[addOn.Name], app.App.Name)
+			}
+			for _, attached := range app.AttachedAddOns {
+				attachedApps[attached.Name] = append(attachedApps[attached.Name], app.App.Name)
+			}
+		}
+	}
+
+	var orgs []string
+	for org := range addOnsByOrg {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	services := make(map[string]*AddOnServiceUsage)
+	seen := make(map[string]bool)
+	for _, org := range orgs {
+		for _, addOn := range addOnsByOrg[org] {
+			if seen[addOn.ID] {
+				continue
+			}
+			seen[addOn.ID] = true
+
+			inventoryAddOn := InventoryAddOn{
+				Name:         addOn.Name,
+				Service:      addOn.AddonService.Name,
+				Plan:         addOn.Plan.Name,
+				State:        addOn.State,
+				Organization: org,
+				BillingApp:   addOn.App.Name,
+				AttachedApps: attachedApps[addOn.Name],
+				CreatedAt:    addOn.CreatedAt,
+				MonthlyCost:  AddOnMonthlyCost(addOn),
+			}
+			sort.Strings(inventoryAddOn.AttachedApps)
+			switch addOn.State {
+			case AddOnStateDeprovisioned:
+				inventoryAddOn.Flags = append(inventoryAddOn.Flags, AddOnFlagDeprovisioned)
+			case AddOnStateProvisioning:
+				inventoryAddOn.Flags = append(inventoryAddOn.Flags, AddOnFlagProvisioning)
+			}
+			if billingApp, ok := apps[addOn.App.Name]; ok {
+				inventoryAddOn.Region = billingApp.App.Region.Name
+				if len(billingApp.Dynos) == 0 {
+					inventoryAddOn.Flags = append(inventoryAddOn.Flags, AddOnFlagBillingAppNoDynos)
+				}
+			}
+			inventory.AddOns = append(inventory.AddOns, inventoryAddOn)
+
+			usage, ok := services[inventoryAddOn.Service]
+			if !ok {
+				usage = &AddOnServiceUsage{Service: inventoryAddOn.Service}
+				services[inventoryAddOn.Service] = usage
+			}
+			usage.Total++
+			usage.MonthlyCost += inventoryAddOn.MonthlyCost
+		}
+	}
+
+	for _, usage := range services {
+		inventory.Services = append(inventory.Services, *usage)
+	}
+	sort.Slice(inventory.Teams, func(i, j int) bool {
+		return inventory.Teams[i].Team < inventory.Teams[j].Team
+	})
+	sort.Slice(inventory.Services, func(i, j int) bool {
+		return inventory.Services[i].Service < inventory.Services[j].Service
+	})
+	sort.Slice(inventory.AddOns, func(i, j int) bool {
+		if inventory.AddOns[i].Service != inventory.AddOns[j].Service {
+			return inventory.AddOns[i].Service < inventory.AddOns[j].Service
+		}
+		return inventory.AddOns[i].Name < inventory.AddOns[j].Name
+	})
+	return inventory
+}
+
+//FlaggedAddOns Add-ons with at least one flag
+func (i AddOnInventory) FlaggedAddOns() []InventoryAddOn {
+	var flagged []InventoryAddOn
+	for _, addOn := range i.AddOns {
+		if len(addOn.Flags) > 0 {
+			flagged = append(flagged, addOn)
+		}
+	}
+	return flagged
+}
@@ -0,0 +1,32 @@
+package herokuls
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+func TestBuildAddOnInventoryKeepsFailedTeams(t *testing.T) {
+	herokuOrgs := []HerokuOrganization{
+		{org: heroku.Organization{Name: "shop"}},
+		{org: heroku.Organization{Name: "legacy"}},
+	}
+	addOnsByOrg := map[string][]heroku.AddOn{
+		"shop": decodeAddOns(t, `[{"id":"1","name":"redis-1","addon_service":{"name":"heroku-redis"},"app":{"name":"shop-web"},"billed_price":{"cents":1500}}]`),
+	}
+	errorsByOrg := map[string]error{"legacy": errors.New("forbidden")}
+
+	inventory := BuildAddOnInventory(herokuOrgs, addOnsByOrg, errorsByOrg)
+	wantTeams := []AddOnTeam{
+		{Team: "legacy", Error: "forbidden"},
+		{Team: "shop", AddOns: 1},
+	}
+	if !reflect.DeepEqual(inventory.Teams, wantTeams) {
+		t.Errorf("Teams = %+v, want %+v", inventory.Teams, wantTeams)
+	}
+	if len(inventory.AddOns) != 1 || inventory.AddOns[0].Organization != "shop" || inventory.AddOns[0].MonthlyCost != 15 {
+		t.Errorf("AddOns = %+v, want redis-1 of shop at 15$", inventory.AddOns)
+	}
+}
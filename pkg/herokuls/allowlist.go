@@ -0,0 +1,183 @@
+package herokuls
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// AllowlistFlagNoAllowlist team does not restrict add-ons to an allowlist
+	AllowlistFlagNoAllowlist = "no-allowlist"
+	// AllowlistFlagDrift heroku allowlist of the team differs from the desired allowlist
+	AllowlistFlagDrift = "allowlist-drift"
+	// AllowlistFlagError heroku allowlist of the team could not be read, its add-ons are not checked
+	AllowlistFlagError = "allowlist-error"
+
+	// AllowlistReasonNotAllowlisted add-on service is not on the heroku allowlist of the team
+	AllowlistReasonNotAllowlisted = "not-allowlisted"
+	// AllowlistReasonNotDesired add-on service is not on the desired allowlist
+	AllowlistReasonNotDesired = "not-desired"
+)
+
+//DesiredAllowlist Add-on services we want teams to allow
+//Teams override Services for a team
+type DesiredAllowlist struct {
+	Services []string            `yaml:"services"`
+	Teams    map[string][]string `yaml:"teams"`
+}
+
+//TeamAllowlist Heroku allowlist of a team compared to the desired allowlist and to the add-ons in use
+type TeamAllowlist struct {
+	Team               string              `json:"team"`
+	Allowlist          []string            `json:"allowlist"`
+	Desired            []string            `json:"desired,omitempty"`
+	AllowedNotDesired  []string            `json:"allowed_not_desired,omitempty"`
+	DesiredNotAllowed  []string            `json:"desired_not_allowed,omitempty"`
+	NonCompliantAddOns []NonCompliantAddOn `json:"non_compliant_addons"`
+	Flags              []string            `json:"flags"`
+	Error              string              `json:"error,omitempty"`
+}
+
+//NonCompliantAddOn Add-on whose service is outside the heroku or the desired allowlist
+type NonCompliantAddOn struct {
+	App     string   `json:"application"`
+	AddOn   string   `json:"addon"`
+	Service string   `json:"service"`
+	Reasons []string `json:"reasons"`
+}
+
+//LoadDesiredAllowlist Read a yaml desired allowlist file
+func LoadDesiredAllowlist(r io.Reader) (DesiredAllowlist, error) {
+	var desired DesiredAllowlist
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return desired, err
+	}
+	err = yaml.Unmarshal(b, &desired)
+	return desired, err
+}
+
+//ForTeam Desired allowlist of a team, nil when nothing is desired
+func (d DesiredAllowlist) ForTeam(team string) []string {
+	if services, ok := d.Teams[team]; ok {
+		return services
+	}
+	return d.Services
+}
+
+//CheckAddOnAllowlists Compare the allowlist of every organization with the add-ons in use and the desired allowlist
+//Teams whose allowlist cannot be read are kept with AllowlistFlagError
+func (hls *HerokuListing) CheckAddOnAllowlists(herokuOrgs []HerokuOrganization, desired DesiredAllowlist) ([]TeamAllowlist, error) {
+	var teamsAllowlist []TeamAllowlist
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, len(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		wg.Add(1)
+		go func(org HerokuOrganization) {
+			defer wg.Done()
+			rl.Take()
+			var teamAllowlist TeamAllowlist
+			whitelisted, err := hls.Cli.WhitelistedAddOnServiceListByTeam(hls.ctx, org.Name(), &heroku.ListRange{Field: "id"})
+			if err != nil {
+				errChannel <- err
+				teamAllowlist = TeamAllowlist{
+					Team:  org.Name(),
+					Flags: []string{AllowlistFlagError},
+					Error: err.Error(),
+				}
+			} else {
+				var allowlist []string
+				for _, service := range whitelisted {
+					allowlist = append(allowlist, service.AddonService.Name)
+				}
+				teamAllowlist = CheckTeamAllowlist(org, allowlist, desired.ForTeam(org.Name()))
+			}
+			mutex.Lock()
+			teamsAllowlist = append(teamsAllowlist, teamAllowlist)
+			mutex.Unlock()
+		}(org)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(teamsAllowlist, func(i, j int) bool {
+		return teamsAllowlist[i].Team < teamsAllowlist[j].Team
+	})
+	return teamsAllowlist, <-errChannel
+}
+
+//CheckTeamAllowlist Check the add-ons billed to the apps of an organization
+//An empty allowlist means heroku does not restrict add-ons, an empty desired allowlist is not checked
+func CheckTeamAllowlist(org HerokuOrganization, allowlist []string, desired []string) TeamAllowlist {
+	teamAllowlist := TeamAllowlist{
+		Team:      org.Name(),
+		Allowlist: append([]string{}, allowlist...),
+		Desired:   desired,
+	}
+	sort.Strings(teamAllowlist.Allowlist)
+	if len(allowlist) == 0 {
+		teamAllowlist.Flags = append(teamAllowlist.Flags, AllowlistFlagNoAllowlist)
+	}
+	if len(desired) > 0 {
+		teamAllowlist.AllowedNotDesired = missingServices(allowlist, desired)
+		teamAllowlist.DesiredNotAllowed = missingServices(desired, allowlist)
+		if len(teamAllowlist.AllowedNotDesired) > 0 || len(teamAllowlist.DesiredNotAllowed) > 0 {
+			teamAllowlist.Flags = append(teamAllowlist.Flags, AllowlistFlagDrift)
+		}
+	}
+
+	for _, app := range org.Apps {
+		for _, addOn := range app.AddOns {
+			var reasons []string
+			if len(allowlist) > 0 && !stringInSlice(addOn.AddonService.Name, allowlist) {
+				reasons = append(reasons, AllowlistReasonNotAllowlisted)
+			}
+			if len(desired) > 0 && !stringInSlice(addOn.AddonService.Name, desired) {
+				reasons = append(reasons, AllowlistReasonNotDesired)
+			}
+			if len(reasons) == 0 {
+				continue
+			}
+			teamAllowlist.NonCompliantAddOns = append(teamAllowlist.NonCompliantAddOns, NonCompliantAddOn{
+				App:     app.App.Name,
+				AddOn:   addOn.Name,
+				Service: addOn.AddonService.Name,
+				Reasons: reasons,
+			})
+		}
+	}
+	return teamAllowlist
+}
+
+//AllowlistViolations Number of non compliant add-ons and of teams without allowlist
+func AllowlistViolations(teamsAllowlist []TeamAllowlist) (addOns int, teamsWithoutAllowlist int) {
+	for _, teamAllowlist := range teamsAllowlist {
+		addOns += len(teamAllowlist.NonCompliantAddOns)
+		if stringInSlice(AllowlistFlagNoAllowlist, teamAllowlist.Flags) {
+			teamsWithoutAllowlist++
+		}
+	}
+	return addOns, teamsWithoutAllowlist
+}
+
+// missingServices services of list missing from reference, sorted
+func missingServices(list []string, reference []string) []string {
+	var missing []string
+	for _, service := range list {
+		if !stringInSlice(service, reference) {
+			missing = append(missing, service)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
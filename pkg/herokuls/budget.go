@@ -0,0 +1,120 @@
+package herokuls
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// BudgetScopeOrganization budget of an organization
+	BudgetScopeOrganization = "organization"
+	// BudgetScopeCostCenter budget of a cost center
+	BudgetScopeCostCenter = "cost-center"
+)
+
+//BudgetConfig Monthly limit in $ by organization and by cost center
+type BudgetConfig struct {
+	Organizations map[string]float64 `yaml:"organizations"`
+	CostCenters   map[string]float64 `yaml:"cost_centers"`
+}
+
+//BudgetStatus Actual monthly cost compared to the budget
+type BudgetStatus struct {
+	Scope           string  `json:"scope"`
+	Name            string  `json:"name"`
+	Budget          float64 `json:"budget"`
+	Actual          float64 `json:"actual"`
+	PercentConsumed float64 `json:"percent_consumed"`
+	Exceeded        bool    `json:"exceeded"`
+	Unknown         bool    `json:"unknown"`
+}
+
+//LoadBudgetConfig Read a yaml budget file
+func LoadBudgetConfig(r io.Reader) (BudgetConfig, error) {
+	var config BudgetConfig
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return config, err
+	}
+	err = yaml.Unmarshal(b, &config)
+	return config, err
+}
+
+//OrganizationCosts Monthly cost of dynos and add-ons by organization, every listed organization has a cost
+func OrganizationCosts(herokuOrgs []HerokuOrganization, pricing DynoPricing) map[string]float64 {
+	costs := make(map[string]float64, len(herokuOrgs))
+	for _, org := range herokuOrgs {
+		var cost float64
+		for _, app := range org.Apps {
+			dynoCost, addOnCost := AppMonthlyCost(app, pricing)
+			cost += dynoCost + addOnCost
+		}
+		costs[org.Name()] += cost
+	}
+	return costs
+}
+
+//EvaluateBudgets Compare actual costs with every configured budget
+//A budget whose organization or cost center has no cost is Unknown, a typo must not pass as a budget consumed at 0%
+func EvaluateBudgets(config BudgetConfig, orgCosts map[string]float64, costCenterCosts []CostCenterCost) []BudgetStatus {
+	var statuses []BudgetStatus
+	for org, budget := range config.Organizations {
+		actual, ok := orgCosts[org]
+		statuses = append(statuses, newBudgetStatus(BudgetScopeOrganization, org, budget, actual, !ok))
+	}
+	centerCosts := make(map[string]float64, len(costCenterCosts))
+	for _, cost := range costCenterCosts {
+		centerCosts[cost.CostCenter] = cost.Total
+	}
+	for costCenter, budget := range config.CostCenters {
+		actual, ok := centerCosts[costCenter]
+		statuses = append(statuses, newBudgetStatus(BudgetScopeCostCenter, costCenter, budget, actual, !ok))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Scope != statuses[j].Scope {
+			return statuses[i].Scope > statuses[j].Scope
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+func newBudgetStatus(scope, name string, budget, actual float64, unknown bool) BudgetStatus {
+	status := BudgetStatus{
+		Scope:    scope,
+		Name:     name,
+		Budget:   budget,
+		Actual:   actual,
+		Exceeded: actual > budget,
+		Unknown:  unknown,
+	}
+	if budget > 0 {
+		status.PercentConsumed = actual / budget * 100
+	}
+	return status
+}
+
+//ExceededBudgets Budgets whose actual cost is above the limit
+func ExceededBudgets(statuses []BudgetStatus) []BudgetStatus {
+	var exceeded []BudgetStatus
+	for _, status := range statuses {
+		if status.Exceeded {
+			exceeded = append(exceeded, status)
+		}
+	}
+	return exceeded
+}
+
+//UnknownBudgets Budgets whose organization or cost center is not in the listing
+func UnknownBudgets(statuses []BudgetStatus) []BudgetStatus {
+	var unknown []BudgetStatus
+	for _, status := range statuses {
+		if status.Unknown {
+			unknown = append(unknown, status)
+		}
+	}
+	return unknown
+}
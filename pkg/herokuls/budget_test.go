@@ -0,0 +1,44 @@
+package herokuls
+
+import (
+	"testing"
+)
+
+func TestEvaluateBudgets(t *testing.T) {
+	config := BudgetConfig{
+		Organizations: map[string]float64{"acme": 100, "acme-typo": 100, "empty": 10},
+		CostCenters:   map[string]float64{"platform": 50, "unmapped": 50},
+	}
+	orgCosts := map[string]float64{"acme": 150, "empty": 0}
+	costCenterCosts := []CostCenterCost{{CostCenter: "platform", Total: 20}}
+
+	statuses := EvaluateBudgets(config, orgCosts, costCenterCosts)
+	want := map[string]struct{ exceeded, unknown bool }{
+		"acme":      {exceeded: true},
+		"acme-typo": {unknown: true},
+		"empty":     {},
+		"platform":  {},
+		"unmapped":  {unknown: true},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(want))
+	}
+	for _, status := range statuses {
+		if status.Exceeded != want[status.Name].exceeded || status.Unknown != want[status.Name].unknown {
+			t.Errorf("%s: exceeded %t, unknown %t, want %+v", status.Name, status.Exceeded, status.Unknown, want[status.Name])
+		}
+	}
+	if got := len(ExceededBudgets(statuses)); got != 1 {
+		t.Errorf("ExceededBudgets = %d, want 1", got)
+	}
+	if got := len(UnknownBudgets(statuses)); got != 2 {
+		t.Errorf("UnknownBudgets = %d, want 2", got)
+	}
+}
+
+func TestOrganizationCostsListEveryOrganization(t *testing.T) {
+	costs := OrganizationCosts([]HerokuOrganization{{}}, DynoPricing{})
+	if _, ok := costs[HerokuOrganization{}.Name()]; !ok {
+		t.Errorf("organization without apps is missing from %v", costs)
+	}
+}
@@ -0,0 +1,211 @@
+package herokuls
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// BuildFlagOutdatedBuildpack a buildpack of the app is on the outdated list
+	BuildFlagOutdatedBuildpack = "outdated-buildpack"
+	// BuildFlagPinnedBuildpack a buildpack of the app is pinned to a git ref
+	BuildFlagPinnedBuildpack = "pinned-buildpack"
+	// BuildFlagFailing the last build of the app failed
+	BuildFlagFailing = "failing-build"
+	// BuildFlagStackNotRebuilt the last build ran on another stack than the app stack
+	BuildFlagStackNotRebuilt = "stack-not-rebuilt"
+	// BuildFlagLargeSlug the current slug is close to the slug size limit
+	BuildFlagLargeSlug = "large-slug"
+
+	// BuildStatusFailed Build.Status of a failed build
+	BuildStatusFailed = "failed"
+	// BuildStatusPending Build.Status of a running build
+	BuildStatusPending = "pending"
+
+	// SlugSizeLimit maximum compressed slug size accepted by heroku, in bytes
+	SlugSizeLimit = 500 * 1024 * 1024
+
+	// lastReleasesForSlug releases fetched to find the current one
+	lastReleasesForSlug = 5
+)
+
+//BuildOptions Buildpacks considered outdated, matched as a prefix of their name or URL,
+//and the slug size in bytes from which a slug is flagged
+type BuildOptions struct {
+	OutdatedBuildpacks []string
+	SlugWarnSize       int
+}
+
+//AppBuild Buildpacks, last build and current slug of an application
+type AppBuild struct {
+	App             string      `json:"application"`
+	Organization    string      `json:"organization"`
+	Stack           string      `json:"stack"`
+	Buildpacks      []Buildpack `json:"buildpacks"`
+	LastBuildStatus string      `json:"last_build_status"`
+	LastBuildStack  string      `json:"last_build_stack"`
+	LastBuildAt     *time.Time  `json:"last_build_at,omitempty"`
+	BuildSeconds    int         `json:"build_duration_seconds"`
+	SlugSize        int         `json:"slug_size_bytes"`
+	Flags           []string    `json:"flags"`
+}
+
+//Buildpack Installed buildpack, registry buildpacks have a name, the others only an URL
+type Buildpack struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+//String Name of the buildpack, its URL when it has none
+func (b Buildpack) String() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	return b.URL
+}
+
+//HasPrefix return true if the name or the URL of the buildpack start with the prefix
+func (b Buildpack) HasPrefix(prefix string) bool {
+	return (b.Name != "" && strings.HasPrefix(b.Name, prefix)) || strings.HasPrefix(b.URL, prefix)
+}
+
+//ListBuilds Collect the buildpacks, the last build and the current slug size of every application
+func (hls *HerokuListing) ListBuilds(herokuOrgs []HerokuOrganization, opts BuildOptions) ([]AppBuild, error) {
+	var appsBuilds []AppBuild
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				rl.Take()
+				appBuild, err := hls.getBuildbyApp(app)
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				CheckAppBuild(&appBuild, opts)
+				mutex.Lock()
+				appsBuilds = append(appsBuilds, appBuild)
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appsBuilds, func(i, j int) bool {
+		return appsBuilds[i].App < appsBuilds[j].App
+	})
+	return appsBuilds, <-errChannel
+}
+
+//getBuildbyApp Buildpacks, last build and slug of the current release of an application
+func (hls *HerokuListing) getBuildbyApp(app heroku.OrganizationApp) (AppBuild, error) {
+	appBuild := AppBuild{
+		App:          app.Name,
+		Organization: appOrganization(app),
+		Stack:        app.Stack.Name,
+	}
+
+	installations, err := hls.Cli.BuildpackInstallationList(hls.ctx, app.ID, &heroku.ListRange{Field: "ordinal"})
+	if err != nil {
+		return appBuild, err
+	}
+	sort.Slice(installations, func(i, j int) bool {
+		return installations[i].Ordinal < installations[j].Ordinal
+	})
+	for _, installation := range installations {
+		appBuild.Buildpacks = append(appBuild.Buildpacks, Buildpack{
+			Name: installation.Buildpack.Name,
+			URL:  installation.Buildpack.URL,
+		})
+	}
+
+	builds, err := hls.Cli.BuildList(hls.ctx, app.ID, &heroku.ListRange{Field: "created_at", Max: 1, Descending: true})
+	if err != nil {
+		return appBuild, err
+	}
+	if len(builds) > 0 {
+		build := builds[0]
+		appBuild.LastBuildStatus = build.Status
+		appBuild.LastBuildStack = build.Stack
+		appBuild.LastBuildAt = &build.CreatedAt
+		if build.Status != BuildStatusPending {
+			appBuild.BuildSeconds = int(build.UpdatedAt.Sub(build.CreatedAt).Seconds())
+		}
+	}
+
+	releases, err := hls.Cli.ReleaseList(hls.ctx, app.ID, &heroku.ListRange{Field: "version", Max: lastReleasesForSlug, Descending: true})
+	if err != nil {
+		return appBuild, err
+	}
+	for _, release := range releases {
+		if !release.Current {
+			continue
+		}
+		if release.Slug == nil {
+			break
+		}
+		slug, err := hls.Cli.SlugInfo(hls.ctx, app.ID, release.Slug.ID)
+		if err != nil {
+			return appBuild, err
+		}
+		if slug.Size != nil {
+			appBuild.SlugSize = *slug.Size
+		}
+		break
+	}
+	return appBuild, nil
+}
+
+//CheckAppBuild Flag outdated or pinned buildpacks, failing builds, stale stacks and large slugs
+func CheckAppBuild(appBuild *AppBuild, opts BuildOptions) {
+	var outdated, pinned bool
+	for _, buildpack := range appBuild.Buildpacks {
+		for _, prefix := range opts.OutdatedBuildpacks {
+			if buildpack.HasPrefix(prefix) {
+				outdated = true
+			}
+		}
+		if strings.Contains(buildpack.URL, "#") {
+			pinned = true
+		}
+	}
+	if outdated {
+		appBuild.Flags = append(appBuild.Flags, BuildFlagOutdatedBuildpack)
+	}
+	if pinned {
+		appBuild.Flags = append(appBuild.Flags, BuildFlagPinnedBuildpack)
+	}
+	if appBuild.LastBuildStatus == BuildStatusFailed {
+		appBuild.Flags = append(appBuild.Flags, BuildFlagFailing)
+	}
+	if appBuild.LastBuildStack != "" && appBuild.LastBuildStack != appBuild.Stack {
+		appBuild.Flags = append(appBuild.Flags, BuildFlagStackNotRebuilt)
+	}
+	if opts.SlugWarnSize > 0 && appBuild.SlugSize >= opts.SlugWarnSize {
+		appBuild.Flags = append(appBuild.Flags, BuildFlagLargeSlug)
+	}
+}
+
+//FlaggedBuilds Applications with at least one build flag
+func FlaggedBuilds(appsBuilds []AppBuild) []AppBuild {
+	var flagged []AppBuild
+	for _, appBuild := range appsBuilds {
+		if len(appBuild.Flags) > 0 {
+			flagged = append(flagged, appBuild)
+		}
+	}
+	return flagged
+}
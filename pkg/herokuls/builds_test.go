@@ -0,0 +1,28 @@
+package herokuls
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckAppBuildBuildpacks(t *testing.T) {
+	opts := BuildOptions{OutdatedBuildpacks: []string{"heroku/nodejs", "https://github.com/heroku/heroku-buildpack-ruby"}}
+	tests := []struct {
+		name       string
+		buildpacks []Buildpack
+		want       []string
+	}{
+		{name: "outdated name", buildpacks: []Buildpack{{Name: "heroku/nodejs", URL: "https://buildpack-registry.s3.amazonaws.com/buildpacks/heroku/nodejs.tgz"}}, want: []string{BuildFlagOutdatedBuildpack}},
+		{name: "outdated url", buildpacks: []Buildpack{{URL: "https://github.com/heroku/heroku-buildpack-ruby.git"}}, want: []string{BuildFlagOutdatedBuildpack}},
+		{name: "outdated url of a named buildpack", buildpacks: []Buildpack{{Name: "heroku/ruby", URL: "https://github.com/heroku/heroku-buildpack-ruby"}}, want: []string{BuildFlagOutdatedBuildpack}},
+		{name: "pinned url", buildpacks: []Buildpack{{URL: "https://github.com/heroku/heroku-buildpack-go#v150"}}, want: []string{BuildFlagPinnedBuildpack}},
+		{name: "current", buildpacks: []Buildpack{{Name: "heroku/go", URL: "heroku/go"}}},
+	}
+	for _, test := range tests {
+		appBuild := AppBuild{Buildpacks: test.buildpacks}
+		CheckAppBuild(&appBuild, opts)
+		if !reflect.DeepEqual(appBuild.Flags, test.want) {
+			t.Errorf("%s: Flags = %v, want %v", test.name, appBuild.Flags, test.want)
+		}
+	}
+}
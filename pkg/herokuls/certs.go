@@ -0,0 +1,267 @@
+package herokuls
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// CertFlagExpiring certificate expire within the warning window
+	CertFlagExpiring = "expiring"
+	// CertFlagNoACM app has custom domains but ACM is disabled
+	CertFlagNoACM = "custom-domains-without-acm"
+	// CertFlagUncoveredDomain app has custom domains not covered by any certificate
+	CertFlagUncoveredDomain = "uncovered-domains"
+	// CertFlagUnparsable certificate chain of the endpoint cannot be parsed
+	CertFlagUnparsable = "unparsable-chain"
+	// CertFlagListingError certificates or domains of the app could not be listed
+	CertFlagListingError = "listing-error"
+
+	// DomainKindCustom Domain.Kind of a domain which is not managed by heroku
+	DomainKindCustom = "custom"
+	// AcmStatusIssued Domain.AcmStatus once ACM issued the certificate
+	AcmStatusIssued = "cert issued"
+
+	// EndpointTypeSNI certificate from a SNI endpoint
+	EndpointTypeSNI = "sni"
+	// EndpointTypeSSL certificate from a legacy SSL endpoint
+	EndpointTypeSSL = "ssl"
+)
+
+//AppCertificates Certificates and custom domains of an application
+type AppCertificates struct {
+	App              string        `json:"application"`
+	Organization     string        `json:"organization"`
+	Acm              bool          `json:"acm"`
+	Certificates     []Certificate `json:"certificates"`
+	CustomDomains    []string      `json:"custom_domains"`
+	UncoveredDomains []string      `json:"uncovered_domains"`
+	Flags            []string      `json:"flags"`
+	Error            string        `json:"error,omitempty"`
+}
+
+//Certificate Leaf certificate of an endpoint chain
+type Certificate struct {
+	Endpoint      string    `json:"endpoint"`
+	Type          string    `json:"type"`
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	SANs          []string  `json:"sans"`
+	NotAfter      time.Time `json:"not_after"`
+	ExpiresInDays int       `json:"expires_in_days"`
+	Flags         []string  `json:"flags"`
+	Error         string    `json:"error,omitempty"`
+}
+
+//ListCertificates Collect certificates, ACM status and custom domains for every application
+//Certificates expiring within warnDays are flagged, apps which could not be listed are kept with their error
+func (hls *HerokuListing) ListCertificates(herokuOrgs []HerokuOrganization, warnDays int) ([]AppCertificates, error) {
+	var appsCerts []AppCertificates
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+	now := time.Now()
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				rl.Take()
+				appCerts, err := hls.getCertificatesbyApp(app, now, warnDays)
+				if err != nil {
+					errChannel <- err
+					appCerts.Error = err.Error()
+					appCerts.Flags = append(appCerts.Flags, CertFlagListingError)
+				} else if len(appCerts.Certificates) == 0 && len(appCerts.CustomDomains) == 0 {
+					return
+				}
+				mutex.Lock()
+				appsCerts = append(appsCerts, appCerts)
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appsCerts, func(i, j int) bool {
+		if appsCerts[i].Organization != appsCerts[j].Organization {
+			return appsCerts[i].Organization < appsCerts[j].Organization
+		}
+		return appsCerts[i].App < appsCerts[j].App
+	})
+	return appsCerts, <-errChannel
+}
+
+//getCertificatesbyApp Certificates of SNI and SSL endpoints, ACM status and domains of one application
+func (hls *HerokuListing) getCertificatesbyApp(app heroku.OrganizationApp, now time.Time, warnDays int) (AppCertificates, error) {
+	appCerts := AppCertificates{
+		App:          app.Name,
+		Organization: appOrganization(app),
+	}
+
+	appInfo, err := hls.Cli.AppInfo(hls.ctx, app.ID)
+	if err != nil {
+		return appCerts, err
+	}
+	appCerts.Acm = appInfo.Acm
+
+	sniEndpoints, err := hls.Cli.SniEndpointList(hls.ctx, app.ID, &heroku.ListRange{Field: "id"})
+	if err != nil {
+		return appCerts, err
+	}
+	for _, endpoint := range sniEndpoints {
+		appCerts.Certificates = append(appCerts.Certificates, newCertificate(endpoint.Name, EndpointTypeSNI, endpoint.CertificateChain, now, warnDays))
+	}
+
+	sslEndpoints, err := hls.Cli.SSLEndpointList(hls.ctx, app.ID, &heroku.ListRange{Field: "id"})
+	if err != nil {
+		return appCerts, err
+	}
+	for _, endpoint := range sslEndpoints {
+		appCerts.Certificates = append(appCerts.Certificates, newCertificate(endpoint.Name, EndpointTypeSSL, endpoint.CertificateChain, now, warnDays))
+	}
+
+	domains, err := hls.Cli.DomainList(hls.ctx, app.ID, &heroku.ListRange{Field: "hostname"})
+	if err != nil {
+		return appCerts, err
+	}
+	for _, domain := range domains {
+		if domain.Kind != DomainKindCustom {
+			continue
+		}
+		appCerts.CustomDomains = append(appCerts.CustomDomains, domain.Hostname)
+		if stringValue(domain.AcmStatus) == AcmStatusIssued || certificatesCover(appCerts.Certificates, domain.Hostname) {
+			continue
+		}
+		appCerts.UncoveredDomains = append(appCerts.UncoveredDomains, domain.Hostname)
+	}
+
+	if len(appCerts.CustomDomains) > 0 && !appCerts.Acm {
+		appCerts.Flags = append(appCerts.Flags, CertFlagNoACM)
+	}
+	if len(appCerts.UncoveredDomains) > 0 {
+		appCerts.Flags = append(appCerts.Flags, CertFlagUncoveredDomain)
+	}
+	return appCerts, nil
+}
+
+//newCertificate Build a Certificate from the leaf of a PEM chain
+//An unparsable chain is flagged and reported in the Error
+func newCertificate(endpoint, endpointType, chain string, now time.Time, warnDays int) Certificate {
+	certificate := Certificate{
+		Endpoint: endpoint,
+		Type:     endpointType,
+	}
+	leaf, err := ParseCertificateChain(chain)
+	if err != nil {
+		certificate.Error = err.Error()
+		certificate.Flags = append(certificate.Flags, CertFlagUnparsable)
+		return certificate
+	}
+	certificate.Subject = leaf.Subject.CommonName
+	certificate.Issuer = leaf.Issuer.CommonName
+	certificate.SANs = leaf.DNSNames
+	certificate.NotAfter = leaf.NotAfter
+	certificate.ExpiresInDays = int(leaf.NotAfter.Sub(now).Hours() / 24)
+	if certificate.ExpiresInDays <= warnDays {
+		certificate.Flags = append(certificate.Flags, CertFlagExpiring)
+	}
+	return certificate
+}
+
+//ParseCertificateChain Return the first certificate of a PEM encoded chain
+func ParseCertificateChain(chain string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(chain))
+	if block == nil {
+		return nil, errors.New("no PEM certificate found in chain")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+//certificatesCover return true if one of the certificates is valid for the hostname
+//Unparsable certificates cover nothing
+func certificatesCover(certificates []Certificate, hostname string) bool {
+	for _, certificate := range certificates {
+		if certificate.Error != "" {
+			continue
+		}
+		names := append([]string{certificate.Subject}, certificate.SANs...)
+		for _, name := range names {
+			if matchHostname(name, hostname) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//matchHostname Compare a certificate name with a hostname, a wildcard match a single label
+func matchHostname(name, hostname string) bool {
+	name = strings.ToLower(name)
+	hostname = strings.ToLower(hostname)
+	if !strings.HasPrefix(name, "*.") {
+		return name == hostname
+	}
+	dot := strings.Index(hostname, ".")
+	return dot > 0 && hostname[dot:] == name[1:]
+}
+
+//ExpiringCertificates Number of certificates flagged as expiring
+func ExpiringCertificates(appsCerts []AppCertificates) int {
+	var total int
+	for _, appCerts := range appsCerts {
+		for _, certificate := range appCerts.Certificates {
+			if stringInSlice(CertFlagExpiring, certificate.Flags) {
+				total++
+			}
+		}
+	}
+	return total
+}
+
+//CertificateFindings Expiring or unparsable certificates, uncovered domains and apps which could not be listed are errors,
+//custom domains without ACM warnings
+func CertificateFindings(appsCerts []AppCertificates) findings.Report {
+	report := findings.NewReport("certs",
+		findings.Rule{ID: CertFlagExpiring, Description: "certificates must not expire within the warning window"},
+		findings.Rule{ID: CertFlagUncoveredDomain, Description: "custom domains must be covered by a certificate"},
+		findings.Rule{ID: CertFlagNoACM, Description: "apps with custom domains should enable ACM"},
+		findings.Rule{ID: CertFlagUnparsable, Description: "certificate chains must be parsable"},
+		findings.Rule{ID: CertFlagListingError, Description: "certificates of every app must be listed"},
+	)
+	for _, appCerts := range appsCerts {
+		target := appCerts.Organization + "/" + appCerts.App
+		for _, certificate := range appCerts.Certificates {
+			if stringInSlice(CertFlagExpiring, certificate.Flags) {
+				report.Add(CertFlagExpiring, findings.LevelError, target, fmt.Sprintf("certificate %s of %s expires in %d days", certificate.Subject, certificate.Endpoint, certificate.ExpiresInDays))
+			}
+			if certificate.Error != "" {
+				report.Add(CertFlagUnparsable, findings.LevelError, target, fmt.Sprintf("certificate of %s: %s", certificate.Endpoint, certificate.Error))
+			}
+		}
+		if appCerts.Error != "" {
+			report.Add(CertFlagListingError, findings.LevelError, target, appCerts.Error)
+		}
+		if len(appCerts.UncoveredDomains) > 0 {
+			report.Add(CertFlagUncoveredDomain, findings.LevelError, target, "not covered: "+strings.Join(appCerts.UncoveredDomains, ", "))
+		}
+		if stringInSlice(CertFlagNoACM, appCerts.Flags) {
+			report.Add(CertFlagNoACM, findings.LevelWarning, target, "custom domains without ACM")
+		}
+	}
+	return report
+}
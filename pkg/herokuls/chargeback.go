@@ -0,0 +1,167 @@
+package herokuls
+
+import (
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"sync"
+
+	"go.uber.org/ratelimit"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// CostCenterUnallocated cost center of applications matching no rule
+	CostCenterUnallocated = "unallocated"
+)
+
+//CostCenterMapping Map applications to cost centers
+//The value of ConfigVar wins over the rules, the first matching rule wins over the next ones
+type CostCenterMapping struct {
+	ConfigVar string           `yaml:"config_var"`
+	Rules     []CostCenterRule `yaml:"rules"`
+}
+
+//CostCenterRule Regular expressions an application must all match to belong to the cost center
+//An empty expression match everything
+type CostCenterRule struct {
+	CostCenter   string `yaml:"cost_center"`
+	App          string `yaml:"app"`
+	Organization string `yaml:"organization"`
+	Pipeline     string `yaml:"pipeline"`
+
+	app          *regexp.Regexp
+	organization *regexp.Regexp
+	pipeline     *regexp.Regexp
+}
+
+//CostCenterCost Monthly cost of the applications of a cost center
+type CostCenterCost struct {
+	CostCenter string   `json:"cost_center"`
+	Apps       []string `json:"applications"`
+	DynoCost   float64  `json:"dyno_cost"`
+	AddOnCost  float64  `json:"addon_cost"`
+	Total      float64  `json:"total"`
+}
+
+//LoadCostCenterMapping Read and compile a yaml mapping file
+func LoadCostCenterMapping(r io.Reader) (CostCenterMapping, error) {
+	var mapping CostCenterMapping
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return mapping, err
+	}
+	if err := yaml.Unmarshal(b, &mapping); err != nil {
+		return mapping, err
+	}
+	for i := range mapping.Rules {
+		rule := &mapping.Rules[i]
+		if rule.app, err = compileOptional(rule.App); err != nil {
+			return mapping, err
+		}
+		if rule.organization, err = compileOptional(rule.Organization); err != nil {
+			return mapping, err
+		}
+		if rule.pipeline, err = compileOptional(rule.Pipeline); err != nil {
+			return mapping, err
+		}
+	}
+	return mapping, nil
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+func matchOptional(re *regexp.Regexp, s string) bool {
+	return re == nil || re.MatchString(s)
+}
+
+//usesPipeline return true if a rule needs the pipeline of the applications
+func (m CostCenterMapping) usesPipeline() bool {
+	for _, rule := range m.Rules {
+		if rule.pipeline != nil {
+			return true
+		}
+	}
+	return false
+}
+
+//CostCenter Cost center of an application, configVarValue is the value of ConfigVar if any
+func (m CostCenterMapping) CostCenter(app, organization, pipeline, configVarValue string) string {
+	if configVarValue != "" {
+		return configVarValue
+	}
+	for _, rule := range m.Rules {
+		if matchOptional(rule.app, app) && matchOptional(rule.organization, organization) && matchOptional(rule.pipeline, pipeline) {
+			return rule.CostCenter
+		}
+	}
+	return CostCenterUnallocated
+}
+
+//Chargeback Sum dyno and add-on cost of every application by cost center
+func (hls *HerokuListing) Chargeback(herokuOrgs []HerokuOrganization, mapping CostCenterMapping, pricing DynoPricing) ([]CostCenterCost, error) {
+	appPipelines := make(map[string]AppPipeline)
+	if mapping.usesPipeline() {
+		var err error
+		if appPipelines, err = hls.GetAppPipelines(); err != nil {
+			return []CostCenterCost{}, err
+		}
+	}
+
+	costByCenter := make(map[string]*CostCenterCost)
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app HerokuApp) {
+				defer wg.Done()
+				var configVarValue string
+				if mapping.ConfigVar != "" {
+					rl.Take()
+					configVars, err := hls.Cli.ConfigVarInfoForApp(hls.ctx, app.App.ID)
+					if err != nil {
+						errChannel <- err
+					}
+					configVarValue = stringValue(configVars[mapping.ConfigVar])
+				}
+				costCenter := mapping.CostCenter(app.App.Name, appOrganization(app.App), appPipelines[app.App.ID].Pipeline, configVarValue)
+				dynoCost, addOnCost := AppMonthlyCost(app, pricing)
+
+				mutex.Lock()
+				cost, ok := costByCenter[costCenter]
+				if !ok {
+					cost = &CostCenterCost{CostCenter: costCenter}
+					costByCenter[costCenter] = cost
+				}
+				cost.Apps = append(cost.Apps, app.App.Name)
+				cost.DynoCost += dynoCost
+				cost.AddOnCost += addOnCost
+				cost.Total += dynoCost + addOnCost
+				mutex.Unlock()
+			}(app)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	var costs []CostCenterCost
+	for _, cost := range costByCenter {
+		sort.Strings(cost.Apps)
+		costs = append(costs, *cost)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].CostCenter < costs[j].CostCenter
+	})
+	return costs, <-errChannel
+}
@@ -0,0 +1,212 @@
+package herokuls
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// ConfigClassURL value is an URL
+	ConfigClassURL = "url"
+	// ConfigClassCredential key or value looks like a secret
+	ConfigClassCredential = "credential"
+	// ConfigClassBoolean value is a boolean
+	ConfigClassBoolean = "boolean"
+	// ConfigClassNumber value is a number
+	ConfigClassNumber = "number"
+	// ConfigClassText value is none of the above
+	ConfigClassText = "text"
+
+	// fingerprintLength number of hex characters kept from the HMAC
+	fingerprintLength = 16
+)
+
+var credentialKey = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSWORD|PASSWD|PWD|PRIVATE|CREDENTIAL|API_?KEY|ACCESS_?KEY|AUTH|DSN|SALT)`)
+
+//AppConfig Config var keys of an application, values are never kept
+type AppConfig struct {
+	App          string      `json:"application"`
+	Organization string      `json:"organization"`
+	Vars         []ConfigVar `json:"config_vars"`
+}
+
+//ConfigVar Key of a config var with the fingerprint and classification of its value
+type ConfigVar struct {
+	Key         string   `json:"key"`
+	Fingerprint string   `json:"fingerprint"`
+	Classes     []string `json:"classes"`
+}
+
+//SharedConfigValue Config vars of several applications holding the same value
+type SharedConfigValue struct {
+	Fingerprint string   `json:"fingerprint"`
+	Keys        []string `json:"keys"`
+	Apps        []string `json:"applications"`
+}
+
+//ListConfigVars Collect config var keys for every application
+//Values are fingerprinted with a salted HMAC and dropped, they never leave this function
+func (hls *HerokuListing) ListConfigVars(herokuOrgs []HerokuOrganization, salt string) ([]AppConfig, error) {
+	var appsConfig []AppConfig
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				rl.Take()
+				configVars, err := hls.Cli.ConfigVarInfoForApp(hls.ctx, app.ID)
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				appConfig := AppConfig{
+					App:          app.Name,
+					Organization: appOrganization(app),
+				}
+				for key, value := range configVars {
+					appConfig.Vars = append(appConfig.Vars, NewConfigVar(key, value, salt))
+				}
+				sort.Slice(appConfig.Vars, func(i, j int) bool {
+					return appConfig.Vars[i].Key < appConfig.Vars[j].Key
+				})
+				mutex.Lock()
+				appsConfig = append(appsConfig, appConfig)
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appsConfig, func(i, j int) bool {
+		return appsConfig[i].App < appsConfig[j].App
+	})
+	return appsConfig, <-errChannel
+}
+
+//NewConfigVar Fingerprint and classify a config var value
+func NewConfigVar(key string, value *string, salt string) ConfigVar {
+	configVar := ConfigVar{Key: key}
+	if value == nil {
+		return configVar
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(*value))
+	configVar.Fingerprint = hex.EncodeToString(mac.Sum(nil))[:fingerprintLength]
+	configVar.Classes = classifyConfigVar(key, *value)
+	return configVar
+}
+
+//classifyConfigVar Guess what kind of value a config var hold
+func classifyConfigVar(key, value string) []string {
+	var classes []string
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		classes = append(classes, ConfigClassURL)
+		if _, hasPassword := u.User.Password(); hasPassword {
+			classes = append(classes, ConfigClassCredential)
+		}
+	} else if _, err := strconv.ParseBool(strings.ToLower(value)); err == nil && !isNumber(value) {
+		classes = append(classes, ConfigClassBoolean)
+	} else if isNumber(value) {
+		classes = append(classes, ConfigClassNumber)
+	} else {
+		classes = append(classes, ConfigClassText)
+	}
+	if credentialKey.MatchString(key) && !stringInSlice(ConfigClassCredential, classes) && !stringInSlice(ConfigClassBoolean, classes) {
+		classes = append(classes, ConfigClassCredential)
+	}
+	return classes
+}
+
+//RandomSalt Salt used when none is configured, fingerprints are then only comparable within one run
+func RandomSalt() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+//FilterConfigByKey Keep only applications defining the key, with this key only
+func FilterConfigByKey(appsConfig []AppConfig, key string) []AppConfig {
+	var filtered []AppConfig
+	for _, appConfig := range appsConfig {
+		for _, configVar := range appConfig.Vars {
+			if configVar.Key == key {
+				filtered = append(filtered, AppConfig{
+					App:          appConfig.App,
+					Organization: appConfig.Organization,
+					Vars:         []ConfigVar{configVar},
+				})
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+//SharedConfigValues Group config vars by fingerprint when the same value is used by more than one application
+//Only credential like values are considered when onlyCredentials is set
+func SharedConfigValues(appsConfig []AppConfig, onlyCredentials bool) []SharedConfigValue {
+	sharedByFingerprint := make(map[string]*SharedConfigValue)
+	for _, appConfig := range appsConfig {
+		for _, configVar := range appConfig.Vars {
+			if configVar.Fingerprint == "" {
+				continue
+			}
+			if onlyCredentials && !stringInSlice(ConfigClassCredential, configVar.Classes) {
+				continue
+			}
+			shared, ok := sharedByFingerprint[configVar.Fingerprint]
+			if !ok {
+				shared = &SharedConfigValue{Fingerprint: configVar.Fingerprint}
+				sharedByFingerprint[configVar.Fingerprint] = shared
+			}
+			if !stringInSlice(configVar.Key, shared.Keys) {
+				shared.Keys = append(shared.Keys, configVar.Key)
+			}
+			if !stringInSlice(appConfig.App, shared.Apps) {
+				shared.Apps = append(shared.Apps, appConfig.App)
+			}
+		}
+	}
+
+	var sharedValues []SharedConfigValue
+	for _, shared := range sharedByFingerprint {
+		if len(shared.Apps) < 2 {
+			continue
+		}
+		sort.Strings(shared.Keys)
+		sort.Strings(shared.Apps)
+		sharedValues = append(sharedValues, *shared)
+	}
+	sort.Slice(sharedValues, func(i, j int) bool {
+		if len(sharedValues[i].Apps) != len(sharedValues[j].Apps) {
+			return len(sharedValues[i].Apps) > len(sharedValues[j].Apps)
+		}
+		return sharedValues[i].Fingerprint < sharedValues[j].Fingerprint
+	})
+	return sharedValues
+}
@@ -0,0 +1,85 @@
+package herokuls
+
+import (
+	"strings"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+const (
+	// hobbyPlanMarker part of the plan name of hobby add-ons plans
+	hobbyPlanMarker = "hobby"
+)
+
+//AddOnMonthlyCost Monthly price in $ of an add-on, contract prices are billed outside heroku and count as 0
+func AddOnMonthlyCost(addOn heroku.AddOn) float64 {
+	if addOn.BilledPrice == nil || addOn.BilledPrice.Contract {
+		return 0
+	}
+	return centsToDollars(addOn.BilledPrice.Cents)
+}
+
+//IsFreeAddOn return true for free and hobby add-on plans
+//Contract add-ons are priced 0 but paid outside heroku, they are not free, neither are add-ons without a known price
+func IsFreeAddOn(addOn heroku.AddOn) bool {
+	if addOn.BilledPrice != nil && addOn.BilledPrice.Contract {
+		return false
+	}
+	return (addOn.BilledPrice != nil && addOn.BilledPrice.Cents == 0) || strings.Contains(addOn.Plan.Name, hobbyPlanMarker)
+}
+
+//DynoPricing Monthly price of dyno sizes
+//Prices override the dyno units based price, keys are case insensitive dyno size names
+type DynoPricing struct {
+	Units     map[string]int
+	UnitPrice int
+	Prices    map[string]float64
+}
+
+//MonthlyPrice Monthly price in $ of one full time running dyno of this size
+func (p DynoPricing) MonthlyPrice(size string) float64 {
+	for name, price := range p.Prices {
+		if strings.EqualFold(name, size) {
+			return price
+		}
+	}
+	for name, units := range p.Units {
+		if strings.EqualFold(name, size) {
+			return float64(units * p.UnitPrice)
+		}
+	}
+	return 0
+}
+
+//HasPrice true when the size is in the price catalog or has dyno units and a unit price
+func (p DynoPricing) HasPrice(size string) bool {
+	for name := range p.Prices {
+		if strings.EqualFold(name, size) {
+			return true
+		}
+	}
+	if p.UnitPrice == 0 {
+		return false
+	}
+	for name := range p.Units {
+		if strings.EqualFold(name, size) {
+			return true
+		}
+	}
+	return false
+}
+
+//AppMonthlyCost Monthly cost in $ of the running dynos and of the add-ons of an application
+func AppMonthlyCost(app HerokuApp, pricing DynoPricing) (dynoCost float64, addOnCost float64) {
+	for _, dyno := range app.Dynos {
+		dynoCost += pricing.MonthlyPrice(dyno.Size)
+	}
+	for _, addOn := range app.AddOns {
+		addOnCost += AddOnMonthlyCost(addOn)
+	}
+	return dynoCost, addOnCost
+}
+
+func centsToDollars(cents int) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,185 @@
+package herokuls
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// DNSStatusOK hostname point to the heroku DNS target
+	DNSStatusOK = "ok"
+	// DNSStatusDangling hostname does not resolve anymore
+	DNSStatusDangling = "dangling"
+	// DNSStatusMisconfigured hostname resolve somewhere else than the heroku DNS target
+	DNSStatusMisconfigured = "misconfigured"
+	// DNSStatusLookupError hostname could not be resolved, the DNS status is unknown
+	DNSStatusLookupError = "lookup-error"
+
+	// dnsLookupTimeout deadline to resolve one domain and its heroku DNS target
+	dnsLookupTimeout = 5 * time.Second
+)
+
+// Resolver resolve hostnames, *net.Resolver satisfies it
+// Inject a stub to validate domains without a real DNS
+type Resolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+//AppDomain Domain of an application and the result of its DNS validation
+type AppDomain struct {
+	App            string `json:"application"`
+	Organization   string `json:"organization"`
+	Hostname       string `json:"hostname"`
+	Kind           string `json:"kind"`
+	CName          string `json:"cname"`
+	Status         string `json:"status"`
+	AcmStatus      string `json:"acm_status"`
+	DNSStatus      string `json:"dns_status,omitempty"`
+	ResolvedTarget string `json:"resolved_target,omitempty"`
+}
+
+//ListDomains List every domain of the applications
+//Custom domains are validated against their heroku DNS target when a resolver is provided
+func (hls *HerokuListing) ListDomains(herokuOrgs []HerokuOrganization, resolver Resolver) ([]AppDomain, error) {
+	var appDomains []AppDomain
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				rl.Take()
+				domains, err := hls.Cli.DomainList(hls.ctx, app.ID, &heroku.ListRange{Field: "hostname"})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				mutex.Lock()
+				for _, domain := range domains {
+					appDomains = append(appDomains, AppDomain{
+						App:          app.Name,
+						Organization: appOrganization(app),
+						Hostname:     domain.Hostname,
+						Kind:         domain.Kind,
+						CName:        stringValue(domain.CName),
+						Status:       domain.Status,
+						AcmStatus:    stringValue(domain.AcmStatus),
+					})
+				}
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appDomains, func(i, j int) bool {
+		if appDomains[i].App != appDomains[j].App {
+			return appDomains[i].App < appDomains[j].App
+		}
+		return appDomains[i].Hostname < appDomains[j].Hostname
+	})
+	if resolver != nil {
+		ValidateDomainTargets(hls.ctx, resolver, appDomains)
+	}
+	return appDomains, <-errChannel
+}
+
+//ValidateDomainTargets Resolve every custom domain concurrently and set its DNSStatus
+//A domain is ok when its CNAME is the heroku target or when both resolve to a common address (ALIAS/ANAME records)
+//Every domain is resolved within dnsLookupTimeout
+func ValidateDomainTargets(ctx context.Context, resolver Resolver, appDomains []AppDomain) {
+	var wg = &sync.WaitGroup{}
+
+	rl := ratelimit.New(40) // per second
+
+	for i := range appDomains {
+		if appDomains[i].Kind != DomainKindCustom || appDomains[i].CName == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(domain *AppDomain) {
+			defer wg.Done()
+			rl.Take()
+			lookupCtx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+			defer cancel()
+			validateDomainTarget(lookupCtx, resolver, domain)
+		}(&appDomains[i])
+	}
+	wg.Wait()
+}
+
+//validateDomainTarget Resolve a custom domain and set its DNSStatus
+func validateDomainTarget(ctx context.Context, resolver Resolver, domain *AppDomain) {
+	cname, err := resolver.LookupCNAME(ctx, domain.Hostname)
+	if err != nil {
+		domain.DNSStatus = lookupStatus(err)
+		return
+	}
+	domain.ResolvedTarget = strings.TrimSuffix(cname, ".")
+	if strings.EqualFold(domain.ResolvedTarget, domain.CName) {
+		domain.DNSStatus = DNSStatusOK
+		return
+	}
+	hostAddrs, err := resolver.LookupHost(ctx, domain.Hostname)
+	if err != nil {
+		domain.DNSStatus = lookupStatus(err)
+		return
+	}
+	targetAddrs, err := resolver.LookupHost(ctx, domain.CName)
+	if err != nil {
+		domain.DNSStatus = DNSStatusLookupError
+		return
+	}
+	domain.DNSStatus = DNSStatusMisconfigured
+	for _, addr := range hostAddrs {
+		if stringInSlice(addr, targetAddrs) {
+			domain.DNSStatus = DNSStatusOK
+			return
+		}
+	}
+}
+
+//lookupStatus DNS status of a hostname whose lookup failed
+//Only a definitive DNS answer makes it dangling, timeouts and temporary failures are lookup errors
+func lookupStatus(err error) string {
+	if dnsErr, ok := err.(*net.DNSError); ok && !dnsErr.IsTimeout && !dnsErr.IsTemporary {
+		return DNSStatusDangling
+	}
+	return DNSStatusLookupError
+}
+
+//MisconfiguredDomains Number of domains dangling or misconfigured
+func MisconfiguredDomains(appDomains []AppDomain) int {
+	var total int
+	for _, domain := range appDomains {
+		if domain.DNSStatus == DNSStatusDangling || domain.DNSStatus == DNSStatusMisconfigured {
+			total++
+		}
+	}
+	return total
+}
+
+//UnresolvedDomains Number of domains whose lookup failed
+func UnresolvedDomains(appDomains []AppDomain) int {
+	var total int
+	for _, domain := range appDomains {
+		if domain.DNSStatus == DNSStatusLookupError {
+			total++
+		}
+	}
+	return total
+}
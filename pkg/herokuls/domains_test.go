@@ -0,0 +1,93 @@
+package herokuls
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// stubResolver answer lookups from maps, a missing name is not found
+type stubResolver struct {
+	cnames map[string]string
+	hosts  map[string][]string
+	errors map[string]error
+}
+
+func (r stubResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if err, ok := r.errors[host]; ok {
+		return "", err
+	}
+	if cname, ok := r.cnames[host]; ok {
+		return cname, nil
+	}
+	return "", &net.DNSError{Err: "no such host", Name: host}
+}
+
+func (r stubResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if err, ok := r.errors[host]; ok {
+		return nil, err
+	}
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host}
+}
+
+func TestValidateDomainTargets(t *testing.T) {
+	resolver := stubResolver{
+		cnames: map[string]string{
+			"www.example.com":   "www-target.herokudns.com.",
+			"api.example.com":   "elsewhere.example.net.",
+			"apex.example.com":  "apex.example.com.",
+			"other.example.com": "other.example.net.",
+		},
+		hosts: map[string][]string{
+			"apex.example.com":           {"192.0.2.10"},
+			"apex-target.herokudns.com":  {"192.0.2.10", "192.0.2.11"},
+			"other.example.com":          {"198.51.100.1"},
+			"other-target.herokudns.com": {"192.0.2.20"},
+		},
+		errors: map[string]error{
+			"slow.example.com": &net.DNSError{Err: "i/o timeout", Name: "slow.example.com", IsTimeout: true},
+			"down.example.com": context.DeadlineExceeded,
+		},
+	}
+
+	tests := []struct {
+		hostname       string
+		kind           string
+		cname          string
+		wantStatus     string
+		wantResolvedTo string
+	}{
+		{hostname: "www.example.com", kind: DomainKindCustom, cname: "www-target.herokudns.com", wantStatus: DNSStatusOK, wantResolvedTo: "www-target.herokudns.com"},
+		{hostname: "apex.example.com", kind: DomainKindCustom, cname: "apex-target.herokudns.com", wantStatus: DNSStatusOK, wantResolvedTo: "apex.example.com"},
+		{hostname: "other.example.com", kind: DomainKindCustom, cname: "other-target.herokudns.com", wantStatus: DNSStatusMisconfigured, wantResolvedTo: "other.example.net"},
+		{hostname: "gone.example.com", kind: DomainKindCustom, cname: "gone-target.herokudns.com", wantStatus: DNSStatusDangling},
+		{hostname: "slow.example.com", kind: DomainKindCustom, cname: "slow-target.herokudns.com", wantStatus: DNSStatusLookupError},
+		{hostname: "down.example.com", kind: DomainKindCustom, cname: "down-target.herokudns.com", wantStatus: DNSStatusLookupError},
+		{hostname: "app.herokuapp.com", kind: "heroku", wantStatus: ""},
+	}
+
+	var appDomains []AppDomain
+	for _, test := range tests {
+		appDomains = append(appDomains, AppDomain{Hostname: test.hostname, Kind: test.kind, CName: test.cname})
+	}
+	ValidateDomainTargets(context.Background(), resolver, appDomains)
+
+	for i, test := range tests {
+		domain := appDomains[i]
+		if domain.DNSStatus != test.wantStatus {
+			t.Errorf("%s: DNSStatus = %q, want %q", test.hostname, domain.DNSStatus, test.wantStatus)
+		}
+		if domain.ResolvedTarget != test.wantResolvedTo {
+			t.Errorf("%s: ResolvedTarget = %q, want %q", test.hostname, domain.ResolvedTarget, test.wantResolvedTo)
+		}
+	}
+	if got := MisconfiguredDomains(appDomains); got != 2 {
+		t.Errorf("MisconfiguredDomains = %d, want 2", got)
+	}
+	if got := UnresolvedDomains(appDomains); got != 2 {
+		t.Errorf("UnresolvedDomains = %d, want 2", got)
+	}
+}
@@ -0,0 +1,232 @@
+package herokuls
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// DrainFlagNoDrain app does not ship its logs anywhere
+	DrainFlagNoDrain = "no-drain"
+	// DrainFlagUnknownHost drain destination is not on the host allowlist
+	DrainFlagUnknownHost = "unknown-host"
+	// DrainFlagDuplicate app has several drains to the same destination
+	DrainFlagDuplicate = "duplicate"
+
+	// DrainSourceApp drain added to the app
+	DrainSourceApp = "app"
+	// drainSourceAddOnPrefix prefix of the source of drains created by an add-on
+	drainSourceAddOnPrefix = "addon:"
+
+	// redactedCredential replace user info, path segments and query values of drain and webhook URLs
+	redactedCredential = "REDACTED"
+)
+
+//AppDrains Log drains of an application
+type AppDrains struct {
+	App          string   `json:"application"`
+	Organization string   `json:"organization"`
+	Environment  string   `json:"environment,omitempty"`
+	Drains       []Drain  `json:"drains"`
+	Flags        []string `json:"flags"`
+}
+
+//Drain Log drain with its credentials redacted
+type Drain struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Host   string   `json:"host"`
+	Source string   `json:"source"`
+	Flags  []string `json:"flags"`
+}
+
+//DrainOptions Hosts drains may ship logs to, wildcards like *.example.com are accepted
+//An empty allowlist does not check hosts
+type DrainOptions struct {
+	AllowedHosts []string
+}
+
+//ListDrains Collect the log drains of every application and of the add-ons they own
+func (hls *HerokuListing) ListDrains(herokuOrgs []HerokuOrganization, opts DrainOptions) ([]AppDrains, error) {
+	var appsDrains []AppDrains
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app HerokuApp) {
+				defer wg.Done()
+				rl.Take()
+				logDrains, err := hls.getDrainsbyApp(app)
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				appDrains := CheckAppDrains(app, logDrains, opts)
+				mutex.Lock()
+				appsDrains = append(appsDrains, appDrains)
+				mutex.Unlock()
+			}(app)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appsDrains, func(i, j int) bool {
+		if appsDrains[i].Organization != appsDrains[j].Organization {
+			return appsDrains[i].Organization < appsDrains[j].Organization
+		}
+		return appsDrains[i].App < appsDrains[j].App
+	})
+	return appsDrains, <-errChannel
+}
+
+//getDrainsbyApp Drains of an application and of its add-ons, a drain is listed once
+func (hls *HerokuListing) getDrainsbyApp(app HerokuApp) ([]heroku.LogDrain, error) {
+	logDrains, err := hls.Cli.LogDrainList(hls.ctx, app.App.ID, &heroku.ListRange{Field: "id"})
+	if err != nil {
+		return nil, err
+	}
+	drains := []heroku.LogDrain(logDrains)
+	seen := make(map[string]bool, len(drains))
+	for _, drain := range drains {
+		seen[drain.ID] = true
+	}
+	for _, addOn := range app.AddOns {
+		addOnDrains, err := hls.Cli.LogDrainListByAddOn(hls.ctx, addOn.ID, &heroku.ListRange{Field: "id"})
+		if err != nil {
+			return drains, err
+		}
+		for _, drain := range addOnDrains {
+			if seen[drain.ID] {
+				continue
+			}
+			seen[drain.ID] = true
+			if drain.Addon == nil {
+				drain.Addon = &struct {
+					ID   string `json:"id" url:"id,key"`
+					Name string `json:"name" url:"name,key"`
+				}{ID: addOn.ID, Name: addOn.Name}
+			}
+			drains = append(drains, drain)
+		}
+	}
+	return drains, nil
+}
+
+//CheckAppDrains Redact and flag the drains of an application
+func CheckAppDrains(app HerokuApp, logDrains []heroku.LogDrain, opts DrainOptions) AppDrains {
+	appDrains := AppDrains{
+		App:          app.App.Name,
+		Organization: appOrganization(app.App),
+		Environment:  app.Environment,
+	}
+	if len(logDrains) == 0 {
+		appDrains.Flags = append(appDrains.Flags, DrainFlagNoDrain)
+		return appDrains
+	}
+
+	// duplicates are found on the raw URLs, redacted URLs to one host with different tokens look alike
+	destinations := make(map[string]int)
+	rawURLs := make([]string, 0, len(logDrains))
+	for _, logDrain := range logDrains {
+		drain := Drain{
+			ID:     logDrain.ID,
+			Source: DrainSourceApp,
+		}
+		if logDrain.Addon != nil {
+			drain.Source = drainSourceAddOnPrefix + logDrain.Addon.Name
+		}
+		drain.URL, drain.Host = RedactURL(logDrain.URL)
+		if len(opts.AllowedHosts) > 0 && !hostAllowed(drain.Host, opts.AllowedHosts) {
+			drain.Flags = append(drain.Flags, DrainFlagUnknownHost)
+		}
+		rawURL := strings.TrimSuffix(strings.TrimSpace(logDrain.URL), "/")
+		destinations[rawURL]++
+		rawURLs = append(rawURLs, rawURL)
+		appDrains.Drains = append(appDrains.Drains, drain)
+	}
+
+	for i := range appDrains.Drains {
+		drain := &appDrains.Drains[i]
+		if destinations[rawURLs[i]] > 1 {
+			drain.Flags = append(drain.Flags, DrainFlagDuplicate)
+		}
+		for _, flag := range drain.Flags {
+			if !stringInSlice(flag, appDrains.Flags) {
+				appDrains.Flags = append(appDrains.Flags, flag)
+			}
+		}
+	}
+	sort.Slice(appDrains.Drains, func(i, j int) bool {
+		return appDrains.Drains[i].URL < appDrains.Drains[j].URL
+	})
+	return appDrains
+}
+
+//RedactURL Normalize a drain or webhook URL and redact its user info, query values and path segments
+//Only the first of several path segments is kept, tokens are often in the following ones (/services/<token>, /bulk/<token>)
+//Unparsable URLs are fully redacted
+func RedactURL(rawURL string) (redacted string, host string) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return redactedCredential, ""
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.User != nil {
+		u.User = url.User(redactedCredential)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, redactedCredential)
+		}
+		u.RawQuery = query.Encode()
+	}
+	u.Fragment = ""
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		segments := strings.Split(path, "/")
+		for i := range segments {
+			// a lone segment may be the token itself
+			if i > 0 || len(segments) == 1 {
+				segments[i] = redactedCredential
+			}
+		}
+		u.Path = "/" + strings.Join(segments, "/")
+	} else {
+		u.Path = ""
+	}
+	u.RawPath = ""
+	return u.String(), u.Hostname()
+}
+
+//UndrainedApps Applications without any drain
+func UndrainedApps(appsDrains []AppDrains) []AppDrains {
+	var undrained []AppDrains
+	for _, appDrains := range appsDrains {
+		if stringInSlice(DrainFlagNoDrain, appDrains.Flags) {
+			undrained = append(undrained, appDrains)
+		}
+	}
+	return undrained
+}
+
+// hostAllowed return true if the host matches one of the allowed hosts
+func hostAllowed(host string, allowedHosts []string) bool {
+	for _, allowed := range allowedHosts {
+		if matchHostname(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package herokuls
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// EnvironmentProduction production application
+	EnvironmentProduction = "production"
+	// EnvironmentStaging staging application
+	EnvironmentStaging = "staging"
+	// EnvironmentDevelopment development application
+	EnvironmentDevelopment = "development"
+	// EnvironmentReview review application
+	EnvironmentReview = "review"
+	// EnvironmentTest test or qa application
+	EnvironmentTest = "test"
+	// EnvironmentUnknown no rule could classify the application
+	EnvironmentUnknown = "unknown"
+)
+
+//EnvironmentClassifier Derive the environment of an application
+//The value of ConfigVar wins, then the pipeline stage, then the first matching name suffix
+type EnvironmentClassifier struct {
+	ConfigVar   string
+	UsePipeline bool
+	Suffixes    []EnvironmentSuffix
+}
+
+//EnvironmentSuffix Application name suffix of an environment
+type EnvironmentSuffix struct {
+	Suffix      string
+	Environment string
+}
+
+//EnvironmentGroup Applications of an environment with their monthly cost subtotal
+type EnvironmentGroup struct {
+	Environment string      `json:"environment"`
+	Apps        []HerokuApp `json:"applications"`
+	DynoUnits   int         `json:"dyno_units"`
+	DynoCost    float64     `json:"dyno_cost"`
+	AddOnCost   float64     `json:"addon_cost"`
+}
+
+//DefaultEnvironmentClassifier Classify with pipeline stages and the usual name suffixes
+func DefaultEnvironmentClassifier() EnvironmentClassifier {
+	return EnvironmentClassifier{
+		UsePipeline: true,
+		Suffixes: []EnvironmentSuffix{
+			{Suffix: "-production", Environment: EnvironmentProduction},
+			{Suffix: "-prod", Environment: EnvironmentProduction},
+			{Suffix: "-prd", Environment: EnvironmentProduction},
+			{Suffix: "-staging", Environment: EnvironmentStaging},
+			{Suffix: "-stage", Environment: EnvironmentStaging},
+			{Suffix: "-stg", Environment: EnvironmentStaging},
+			{Suffix: "-development", Environment: EnvironmentDevelopment},
+			{Suffix: "-dev", Environment: EnvironmentDevelopment},
+			{Suffix: "-review", Environment: EnvironmentReview},
+			{Suffix: "-qa", Environment: EnvironmentTest},
+			{Suffix: "-test", Environment: EnvironmentTest},
+		},
+	}
+}
+
+//Classify Environment of an application from its name, pipeline stage and config var value
+func (c EnvironmentClassifier) Classify(appName, pipelineStage, configVarValue string) string {
+	if configVarValue != "" {
+		return c.Normalize(configVarValue)
+	}
+	if c.UsePipeline && pipelineStage != "" {
+		return pipelineStage
+	}
+	name := strings.ToLower(appName)
+	for _, suffix := range c.Suffixes {
+		if strings.HasSuffix(name, suffix.Suffix) {
+			return suffix.Environment
+		}
+	}
+	return EnvironmentUnknown
+}
+
+//Normalize Map an environment name or abbreviation to its environment, "prd" is production
+//Abbreviations are the name suffixes without their dash, unknown names are only lowercased
+func (c EnvironmentClassifier) Normalize(environment string) string {
+	environment = strings.ToLower(strings.TrimSpace(environment))
+	for _, suffix := range c.Suffixes {
+		if strings.TrimPrefix(suffix.Suffix, "-") == environment {
+			return suffix.Environment
+		}
+	}
+	return environment
+}
+
+//ClassifyEnvironments Set the Environment of every application of the organizations
+//When pipelines or config vars cannot be listed the other rules still classify every app and the error is returned
+func (hls *HerokuListing) ClassifyEnvironments(herokuOrgs []HerokuOrganization, classifier EnvironmentClassifier) error {
+	var wg = &sync.WaitGroup{}
+	errChannel := make(chan error, countApps(herokuOrgs)+1)
+
+	appPipelines := make(map[string]AppPipeline)
+	if classifier.UsePipeline {
+		pipelines, err := hls.GetAppPipelines()
+		if err != nil {
+			errChannel <- err
+		} else {
+			appPipelines = pipelines
+		}
+	}
+
+	rl := ratelimit.New(40) // per second
+
+	for i := range herokuOrgs {
+		for j := range herokuOrgs[i].Apps {
+			wg.Add(1)
+			go func(app *HerokuApp) {
+				defer wg.Done()
+				var configVarValue string
+				if classifier.ConfigVar != "" {
+					rl.Take()
+					configVars, err := hls.Cli.ConfigVarInfoForApp(hls.ctx, app.App.ID)
+					if err != nil {
+						errChannel <- err
+					}
+					configVarValue = stringValue(configVars[classifier.ConfigVar])
+				}
+				app.Environment = classifier.Classify(app.App.Name, appPipelines[app.App.ID].Stage, configVarValue)
+			}(&herokuOrgs[i].Apps[j])
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+	return <-errChannel
+}
+
+//GroupByEnvironment Group applications by environment and subtotal their dyno units and cost
+func GroupByEnvironment(herokuOrgs []HerokuOrganization, dynoSize map[string]int, pricing DynoPricing) []EnvironmentGroup {
+	groupsByEnvironment := make(map[string]*EnvironmentGroup)
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			environment := app.Environment
+			if environment == "" {
+				environment = EnvironmentUnknown
+			}
+			group, ok := groupsByEnvironment[environment]
+			if !ok {
+				group = &EnvironmentGroup{Environment: environment}
+				groupsByEnvironment[environment] = group
+			}
+			dynoCost, addOnCost := AppMonthlyCost(app, pricing)
+			group.Apps = append(group.Apps, app)
+			group.DynoUnits += CountTotalDynoUnitByApp(CountDynoTypeByApp(app.Dynos), dynoSize)
+			group.DynoCost += dynoCost
+			group.AddOnCost += addOnCost
+		}
+	}
+
+	var groups []EnvironmentGroup
+	for _, group := range groupsByEnvironment {
+		sort.Slice(group.Apps, func(i, j int) bool {
+			return group.Apps[i].App.Name < group.Apps[j].App.Name
+		})
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Environment < groups[j].Environment
+	})
+	return groups
+}
+
+//NormalizeEnvironments Normalize every environment name, see Normalize
+func (c EnvironmentClassifier) NormalizeEnvironments(environments []string) []string {
+	normalized := make([]string, 0, len(environments))
+	for _, environment := range environments {
+		normalized = append(normalized, c.Normalize(environment))
+	}
+	return normalized
+}
+
+//FilterByEnvironment Keep the applications of the given environments, organizations are kept even when empty
+//Environments are normalized like the classification does, "prod" keeps production apps
+func FilterByEnvironment(herokuOrgs []HerokuOrganization, environments []string) []HerokuOrganization {
+	environments = DefaultEnvironmentClassifier().NormalizeEnvironments(environments)
+	filtered := make([]HerokuOrganization, 0, len(herokuOrgs))
+	for _, org := range herokuOrgs {
+		var apps []HerokuApp
+		for _, app := range org.Apps {
+			if stringInSlice(app.Environment, environments) {
+				apps = append(apps, app)
+			}
+		}
+		org.Apps = apps
+		filtered = append(filtered, org)
+	}
+	return filtered
+}
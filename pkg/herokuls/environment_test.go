@@ -0,0 +1,36 @@
+package herokuls
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+func TestFilterByEnvironmentNormalizesFilters(t *testing.T) {
+	herokuOrgs := []HerokuOrganization{{Apps: []HerokuApp{
+		{App: heroku.OrganizationApp{Name: "shop-production"}, Environment: EnvironmentProduction},
+		{App: heroku.OrganizationApp{Name: "shop-staging"}, Environment: EnvironmentStaging},
+		{App: heroku.OrganizationApp{Name: "shop-qa"}, Environment: EnvironmentTest},
+	}}}
+	tests := []struct {
+		environments []string
+		want         []string
+	}{
+		{environments: []string{"production"}, want: []string{"shop-production"}},
+		{environments: []string{"prod"}, want: []string{"shop-production"}},
+		{environments: []string{" PRD "}, want: []string{"shop-production"}},
+		{environments: []string{"stg", "qa"}, want: []string{"shop-staging", "shop-qa"}},
+		{environments: []string{"preview"}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, app := range FilterByEnvironment(herokuOrgs, test.environments)[0].Apps {
+			got = append(got, app.App.Name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FilterByEnvironment(%s) = %v, want %v", strings.Join(test.environments, ","), got, test.want)
+		}
+	}
+}
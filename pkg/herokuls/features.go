@@ -0,0 +1,153 @@
+package herokuls
+
+import (
+	"sort"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+//FeatureReport Enabled features of every application and team
+//Features are the feature names enabled on at least one application, the columns of the matrix
+type FeatureReport struct {
+	Features []string       `json:"features"`
+	Apps     []AppFeatures  `json:"applications"`
+	Teams    []TeamFeatures `json:"teams"`
+}
+
+//AppFeatures Enabled features of an application
+type AppFeatures struct {
+	App          string   `json:"application"`
+	Organization string   `json:"organization"`
+	Environment  string   `json:"environment,omitempty"`
+	Enabled      []string `json:"enabled"`
+}
+
+//TeamFeatures Enabled features of a team
+type TeamFeatures struct {
+	Team    string   `json:"team"`
+	Enabled []string `json:"enabled"`
+}
+
+//LoadAppFeatures Set the enabled Features of every application of the organizations
+func (hls *HerokuListing) LoadAppFeatures(herokuOrgs []HerokuOrganization) error {
+	var wg = &sync.WaitGroup{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for i := range herokuOrgs {
+		for j := range herokuOrgs[i].Apps {
+			wg.Add(1)
+			go func(app *HerokuApp) {
+				defer wg.Done()
+				rl.Take()
+				features, err := hls.Cli.AppFeatureList(hls.ctx, app.App.ID, &heroku.ListRange{Field: "name"})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				app.Features = nil
+				for _, feature := range features {
+					if feature.Enabled {
+						app.Features = append(app.Features, feature.Name)
+					}
+				}
+				sort.Strings(app.Features)
+			}(&herokuOrgs[i].Apps[j])
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+	return <-errChannel
+}
+
+//ListFeatures Collect the enabled features of every application and organization
+//onlyFeatures restrict the report to these features, all features are kept when empty
+//The report is built from the features which could be listed and the first error is returned with it
+func (hls *HerokuListing) ListFeatures(herokuOrgs []HerokuOrganization, onlyFeatures []string) (FeatureReport, error) {
+	teamFeatures := make(map[string][]string, len(herokuOrgs))
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, len(herokuOrgs)+1)
+
+	if err := hls.LoadAppFeatures(herokuOrgs); err != nil {
+		errChannel <- err
+	}
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		wg.Add(1)
+		go func(team string) {
+			defer wg.Done()
+			rl.Take()
+			features, err := hls.Cli.TeamFeatureList(hls.ctx, team, &heroku.ListRange{Field: "name"})
+			if err != nil {
+				errChannel <- err
+				return
+			}
+			var enabled []string
+			for _, feature := range features {
+				if feature.Enabled {
+					enabled = append(enabled, feature.Name)
+				}
+			}
+			mutex.Lock()
+			teamFeatures[team] = enabled
+			mutex.Unlock()
+		}(org.Name())
+	}
+	wg.Wait()
+	close(errChannel)
+
+	return BuildFeatureReport(herokuOrgs, teamFeatures, onlyFeatures), <-errChannel
+}
+
+//BuildFeatureReport Build the apps by features matrix from the loaded application features
+func BuildFeatureReport(herokuOrgs []HerokuOrganization, teamFeatures map[string][]string, onlyFeatures []string) FeatureReport {
+	var report FeatureReport
+	columns := make(map[string]bool)
+	keep := func(features []string) []string {
+		var kept []string
+		for _, feature := range features {
+			if len(onlyFeatures) == 0 || stringInSlice(feature, onlyFeatures) {
+				kept = append(kept, feature)
+			}
+		}
+		sort.Strings(kept)
+		return kept
+	}
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			appFeatures := AppFeatures{
+				App:          app.App.Name,
+				Organization: org.Name(),
+				Environment:  app.Environment,
+				Enabled:      keep(app.Features),
+			}
+			for _, feature := range appFeatures.Enabled {
+				columns[feature] = true
+			}
+			report.Apps = append(report.Apps, appFeatures)
+		}
+	}
+	for team, features := range teamFeatures {
+		report.Teams = append(report.Teams, TeamFeatures{Team: team, Enabled: keep(features)})
+	}
+
+	// requested features are listed even when no application enabled them
+	for _, feature := range onlyFeatures {
+		columns[feature] = true
+	}
+	report.Features = sortedKeys(columns)
+	sort.Slice(report.Apps, func(i, j int) bool {
+		return report.Apps[i].App < report.Apps[j].App
+	})
+	sort.Slice(report.Teams, func(i, j int) bool {
+		return report.Teams[i].Team < report.Teams[j].Team
+	})
+	return report
+}
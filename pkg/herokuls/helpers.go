@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 
+	heroku "github.com/heroku/heroku-go/v3"
 	yaml "gopkg.in/yaml.v2"
 )
 
@@ -22,11 +23,47 @@ type IPListItem struct {
 	IPList      []string `yaml:"ips"`
 }
 
+// IPListFilter select which spaces are part of the IP listing
+// An empty field match everything
+type IPListFilter struct {
+	TeamTypes []string
+	Teams     []string
+	Spaces    []string
+}
+
 const (
 	// TeamTypeEnterprise Team.Type is enterprise
 	TeamTypeEnterprise = "enterprise"
+	// TeamTypeTeam Team.Type is team
+	TeamTypeTeam = "team"
+	// UnlistedOwnerPrefix prefix of IPListItem.Name when the space owner is not part of TeamList
+	UnlistedOwnerPrefix = "unlisted:"
 )
 
+// matchTeamType return true if the team type is selected
+func (f IPListFilter) matchTeamType(teamType string) bool {
+	return len(f.TeamTypes) == 0 || stringInSlice(teamType, f.TeamTypes)
+}
+
+// matchTeam return true if the team name is selected
+func (f IPListFilter) matchTeam(team string) bool {
+	return len(f.Teams) == 0 || stringInSlice(team, f.Teams)
+}
+
+// matchSpace return true if the space name is selected
+func (f IPListFilter) matchSpace(space string) bool {
+	return len(f.Spaces) == 0 || stringInSlice(space, f.Spaces)
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Yamlize write to file as yaml
 func (ipList *IPList) Yamlize(w io.Writer) error {
 	en := yaml.NewEncoder(w)
@@ -37,3 +74,28 @@ func (ipList *IPList) Yamlize(w io.Writer) error {
 	}
 	return nil
 }
+
+// countApps total number of applications across organizations
+func countApps(herokuOrgs []HerokuOrganization) int {
+	var total int
+	for _, org := range herokuOrgs {
+		total += len(org.Apps)
+	}
+	return total
+}
+
+// appOrganization name of the organization owning the app, empty if none
+func appOrganization(app heroku.OrganizationApp) string {
+	if app.Organization == nil {
+		return ""
+	}
+	return app.Organization.Name
+}
+
+// stringValue dereference an optional string from the API, empty if nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -3,6 +3,7 @@ package herokuls
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sort"
 	"strconv"
 	"sync"
@@ -13,9 +14,11 @@ import (
 
 // HerokuListing Listing Service for Heroku
 // Overload default heroku Service
+// httpClient sends the requests which need the response headers, it must be the client of Cli
 type HerokuListing struct {
-	Cli *heroku.Service
-	ctx context.Context
+	Cli        *heroku.Service
+	ctx        context.Context
+	httpClient *http.Client
 }
 
 //HerokuOrganization Organization and Application
@@ -24,11 +27,30 @@ type HerokuOrganization struct {
 	Apps []HerokuApp `json:"organization_applications"`
 }
 
+//Name Name of the organization
+func (o HerokuOrganization) Name() string {
+	return o.org.Name
+}
+
 //HerokuApp Heroku app with Dynos and Addon
+//AddOns are the add-ons billed to the app, AttachedAddOns the ones billed to another app
+//Environment is only set once ClassifyEnvironments is called, Features once LoadAppFeatures is called
 type HerokuApp struct {
-	App    heroku.OrganizationApp `json:"application"`
-	Dynos  []heroku.Dyno          `json:"application_dynos"`
-	AddOns []heroku.AddOn         `json:"application_addons"`
+	App            heroku.OrganizationApp `json:"application"`
+	Dynos          []heroku.Dyno          `json:"application_dynos"`
+	AddOns         []heroku.AddOn         `json:"application_addons"`
+	AttachedAddOns []AttachedAddOn        `json:"application_attached_addons"`
+	Environment    string                 `json:"application_environment,omitempty"`
+	Features       []string               `json:"application_features,omitempty"`
+}
+
+//AttachedAddOn Reference to an add-on owned and billed by another app
+type AttachedAddOn struct {
+	Name       string `json:"name"`
+	Service    string `json:"service"`
+	Plan       string `json:"plan"`
+	As         string `json:"attachment_name"`
+	BillingApp string `json:"billing_application"`
 }
 
 //DynoTypeByApp
@@ -45,8 +67,9 @@ type AddOnTypeByApp struct {
 
 func NewHerokuListing(herokuCli *heroku.Service) *HerokuListing {
 	return &HerokuListing{
-		Cli: herokuCli,
-		ctx: context.TODO(),
+		Cli:        herokuCli,
+		ctx:        context.TODO(),
+		httpClient: heroku.DefaultClient,
 	}
 }
 
@@ -98,7 +121,8 @@ func (hls *HerokuListing) getAppsbyOrg(organization heroku.Organization) ([]Hero
 
 	var wg = &sync.WaitGroup{}
 	var mutex = &sync.Mutex{}
-	errChannel := make(chan error, len(apps))
+	// dynos, add-ons and attachments can each fail, a full channel would block the goroutine
+	errChannel := make(chan error, 3*len(apps))
 
 	// Heroku have some unclear limit on Request by sec.
 	rl := ratelimit.New(40) // per second
@@ -116,11 +140,17 @@ func (hls *HerokuListing) getAppsbyOrg(organization heroku.Organization) ([]Hero
 			if err != nil {
 				errChannel <- err
 			}
+			attachments, err := hls.getAttachmentsbyApps(app)
+			if err != nil {
+				errChannel <- err
+			}
+			ownedAddOns, attachedAddOns := SplitAddOnsByBillingApp(app.ID, addOns, attachments)
 			mutex.Lock()
 			herokuApps = append(herokuApps, HerokuApp{
-				App:    app,
-				Dynos:  dynos,
-				AddOns: addOns,
+				App:            app,
+				Dynos:          dynos,
+				AddOns:         ownedAddOns,
+				AttachedAddOns: attachedAddOns,
 			})
 			mutex.Unlock()
 		}(app)
@@ -163,6 +193,47 @@ func (hls *HerokuListing) getAddOnsbyApps(app heroku.OrganizationApp) ([]heroku.
 
 }
 
+//getAttachmentsbyApps List add-on attachments of an application
+func (hls *HerokuListing) getAttachmentsbyApps(app heroku.OrganizationApp) ([]heroku.AddOnAttachment, error) {
+	attachmentArr, err := hls.Cli.AddOnAttachmentListByApp(hls.ctx, app.ID, &heroku.ListRange{Field: "id"})
+	var attachments []heroku.AddOnAttachment
+	if err != nil {
+		return attachments, err
+	}
+	for _, attachment := range attachmentArr {
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+//SplitAddOnsByBillingApp Separate the add-ons billed to the app from the ones only attached to it
+//so a shared add-on is counted and priced once, under its billing app
+func SplitAddOnsByBillingApp(appID string, addOns []heroku.AddOn, attachments []heroku.AddOnAttachment) ([]heroku.AddOn, []AttachedAddOn) {
+	attachmentNames := make(map[string]string, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.App.ID == appID {
+			attachmentNames[attachment.Addon.ID] = attachment.Name
+		}
+	}
+
+	var owned []heroku.AddOn
+	var attached []AttachedAddOn
+	for _, addOn := range addOns {
+		if addOn.App.ID == "" || addOn.App.ID == appID {
+			owned = append(owned, addOn)
+			continue
+		}
+		attached = append(attached, AttachedAddOn{
+			Name:       addOn.Name,
+			Service:    addOn.AddonService.Name,
+			Plan:       addOn.Plan.Name,
+			As:         attachmentNames[addOn.ID],
+			BillingApp: addOn.App.Name,
+		})
+	}
+	return owned, attached
+}
+
 func (hls *HerokuListing) GetRateLimitingRemaining() (int, error) {
 	rate, err := hls.Cli.RateLimitInfo(hls.ctx)
 	if err != nil {
@@ -271,35 +342,33 @@ func MergeAddon(addOns []AddOnTypeByApp, dynos []DynoTypeByApp) [][]string {
 	return mergedString
 }
 
-// GetIPList get all ips from all spaces of the user's enterprise teams
-func (hls *HerokuListing) GetIPList(name, description string) *IPList {
-	ts, err := hls.Cli.TeamList(hls.ctx, &heroku.ListRange{Field: "id"})
+// GetIPList get all ips from the spaces selected by the filter
+// Spaces whose owner is not part of TeamList are included and labelled as unlisted
+// The team type filter needs TeamList, an error is returned when it cannot be listed
+func (hls *HerokuListing) GetIPList(name, description string, filter IPListFilter) (*IPList, error) {
+	teams, err := hls.Cli.TeamList(hls.ctx, &heroku.ListRange{Field: "id"})
 	if err != nil {
-		fmt.Println(fmt.Sprintf("Error on TeamList: %v", err))
+		return nil, fmt.Errorf("Error on TeamList: %v", err)
 	}
-	// get only enterprise teams
-	var teams []heroku.Team
-	for _, team := range ts {
-		if team.Type == TeamTypeEnterprise {
-			teams = append(teams, team)
-		}
-	}
-	spaces, err := hls.GetSpacesFromTeams(&teams)
+	ts := []heroku.Team(teams)
+	spaces, err := hls.GetSpacesFromTeams(&ts, filter)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	ipList, err := hls.buildIPListFromSpaces(name, description, &spaces)
-	if err != nil {
-		return nil
+	listedTeams := map[string]bool{}
+	for _, team := range ts {
+		listedTeams[team.ID] = true
 	}
-	return ipList
+	return hls.buildIPListFromSpaces(name, description, &spaces, listedTeams)
 }
 
-// GetSpacesFromTeams get spaces that the provided teams own
-func (hls *HerokuListing) GetSpacesFromTeams(ts *[]heroku.Team) ([]heroku.Space, error) {
-	teams := map[string]bool{}
+// GetSpacesFromTeams get spaces selected by the filter
+// Spaces owned by one of the provided teams are filtered by team type,
+// spaces whose owner is not one of the provided teams are always kept
+func (hls *HerokuListing) GetSpacesFromTeams(ts *[]heroku.Team, filter IPListFilter) ([]heroku.Space, error) {
+	teams := map[string]heroku.Team{}
 	for _, team := range *ts {
-		teams[team.ID] = true
+		teams[team.ID] = team
 	}
 
 	spaces, err := hls.Cli.SpaceList(hls.ctx, &heroku.ListRange{Field: "id"})
@@ -309,15 +378,28 @@ func (hls *HerokuListing) GetSpacesFromTeams(ts *[]heroku.Team) ([]heroku.Space,
 	}
 	var res []heroku.Space
 	for _, space := range spaces {
-		if _, exists := teams[space.Team.ID]; exists {
-			res = append(res, space)
+		if team, exists := teams[space.Team.ID]; exists && !filter.matchTeamType(team.Type) {
+			continue
 		}
+		if !filter.matchTeam(spaceOwner(space)) || !filter.matchSpace(space.Name) {
+			continue
+		}
+		res = append(res, space)
 	}
 	return res, nil
 }
 
+// spaceOwner name of the team owning the space, fallback to the organization
+func spaceOwner(space heroku.Space) string {
+	if space.Team.Name != "" {
+		return space.Team.Name
+	}
+	return space.Organization.Name
+}
+
 // build an IPList instances using heroku.Space info
-func (hls *HerokuListing) buildIPListFromSpaces(name, description string, spaces *[]heroku.Space) (*IPList, error) {
+// listedTeams contains the ID of the teams returned by TeamList
+func (hls *HerokuListing) buildIPListFromSpaces(name, description string, spaces *[]heroku.Space, listedTeams map[string]bool) (*IPList, error) {
 	if spaces == nil { // save the dereference
 		return nil, nil
 	}
@@ -328,7 +410,7 @@ func (hls *HerokuListing) buildIPListFromSpaces(name, description string, spaces
 	}
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
-	errChan := make(chan error)
+	errChan := make(chan error, len(*spaces))
 
 	rl := ratelimit.New(40) // per second
 
@@ -345,10 +427,14 @@ func (hls *HerokuListing) buildIPListFromSpaces(name, description string, spaces
 				return
 			}
 
+			owner := spaceOwner(space)
+			if !listedTeams[space.Team.ID] {
+				owner = UnlistedOwnerPrefix + owner
+			}
 			mutex.Lock()
 			ipList.IPListItems = append(ipList.IPListItems, IPListItem{
-				Name:        fmt.Sprintf("%s/%s", space.Team.Name, space.Name),
-				Description: fmt.Sprintf("IP list from `%s > %s`", space.Team.Name, space.Name),
+				Name:        fmt.Sprintf("%s/%s", owner, space.Name),
+				Description: fmt.Sprintf("IP list from `%s > %s`", owner, space.Name),
 				IPList:      spaceNat.Sources,
 			})
 			mutex.Unlock()
@@ -0,0 +1,44 @@
+package herokuls
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+func TestSplitAddOnsByBillingApp(t *testing.T) {
+	addOns := decodeAddOns(t, `[
+		{"id":"owned","name":"postgresql-owned","app":{"id":"app-1","name":"shop"},"addon_service":{"name":"heroku-postgresql"},"plan":{"name":"heroku-postgresql:standard-0"}},
+		{"id":"no-app","name":"papertrail-legacy","addon_service":{"name":"papertrail"},"plan":{"name":"papertrail:choklad"}},
+		{"id":"shared","name":"redis-shared","app":{"id":"app-2","name":"shop-worker"},"addon_service":{"name":"heroku-redis"},"plan":{"name":"heroku-redis:premium-0"}},
+		{"id":"unnamed","name":"kafka-shared","app":{"id":"app-3","name":"events"},"addon_service":{"name":"heroku-kafka"},"plan":{"name":"heroku-kafka:basic-0"}}
+	]`)
+	var attachments []heroku.AddOnAttachment
+	if err := json.Unmarshal([]byte(`[
+		{"name":"DATABASE","addon":{"id":"owned"},"app":{"id":"app-1"}},
+		{"name":"REDIS","addon":{"id":"shared"},"app":{"id":"app-1"}},
+		{"name":"KAFKA","addon":{"id":"unnamed"},"app":{"id":"app-4"}}
+	]`), &attachments); err != nil {
+		t.Fatal(err)
+	}
+
+	owned, attached := SplitAddOnsByBillingApp("app-1", addOns, attachments)
+
+	var ownedNames []string
+	for _, addOn := range owned {
+		ownedNames = append(ownedNames, addOn.Name)
+	}
+	if want := []string{"postgresql-owned", "papertrail-legacy"}; !reflect.DeepEqual(ownedNames, want) {
+		t.Errorf("owned = %v, want %v", ownedNames, want)
+	}
+	wantAttached := []AttachedAddOn{
+		{Name: "redis-shared", Service: "heroku-redis", Plan: "heroku-redis:premium-0", As: "REDIS", BillingApp: "shop-worker"},
+		// the attachment of another app does not name it
+		{Name: "kafka-shared", Service: "heroku-kafka", Plan: "heroku-kafka:basic-0", BillingApp: "events"},
+	}
+	if !reflect.DeepEqual(attached, wantAttached) {
+		t.Errorf("attached = %+v, want %+v", attached, wantAttached)
+	}
+}
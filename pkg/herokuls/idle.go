@@ -0,0 +1,151 @@
+package herokuls
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// IdleSignalNoRunningDynos app has no dyno running
+	IdleSignalNoRunningDynos = "no-running-dynos"
+	// IdleSignalZeroFormation every process type is scaled to 0
+	IdleSignalZeroFormation = "zero-formation"
+	// IdleSignalStaleRelease last release is older than IdleOptions.StaleDays
+	IdleSignalStaleRelease = "stale-release"
+	// IdleSignalFreeAddOnsOnly app has add-ons, all on free or hobby plans
+	IdleSignalFreeAddOnsOnly = "free-addons-only"
+	// IdleSignalNoCustomDomain app has no custom domain
+	IdleSignalNoCustomDomain = "no-custom-domain"
+)
+
+//IdleOptions When a release is stale and which score make an app a candidate for deletion
+type IdleOptions struct {
+	StaleDays int
+	MinScore  int
+}
+
+//IdleApp Application scored by idle signals, one point by signal
+type IdleApp struct {
+	App              string     `json:"application"`
+	Organization     string     `json:"organization"`
+	Score            int        `json:"score"`
+	Signals          []string   `json:"signals"`
+	LastRelease      *time.Time `json:"last_release,omitempty"`
+	AddOnMonthlyCost float64    `json:"addons_monthly_cost"`
+}
+
+//ListIdleApps Score every application and return candidates for deletion
+//Sorted by score then by the monthly cost they still incur
+func (hls *HerokuListing) ListIdleApps(herokuOrgs []HerokuOrganization, opts IdleOptions) ([]IdleApp, error) {
+	var idleApps []IdleApp
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+	now := time.Now()
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app HerokuApp) {
+				defer wg.Done()
+				rl.Take()
+				formations, err := hls.Cli.FormationList(hls.ctx, app.App.ID, &heroku.ListRange{Field: "id"})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				releases, err := hls.Cli.ReleaseList(hls.ctx, app.App.ID, &heroku.ListRange{Field: "version", Max: 1, Descending: true})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				domains, err := hls.Cli.DomainList(hls.ctx, app.App.ID, &heroku.ListRange{Field: "hostname"})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				var lastRelease *time.Time
+				if len(releases) > 0 {
+					lastRelease = &releases[0].CreatedAt
+				}
+				idleApp := ScoreIdleApp(app, formations, lastRelease, domains, now, opts.StaleDays)
+				if idleApp.Score < opts.MinScore {
+					return
+				}
+				mutex.Lock()
+				idleApps = append(idleApps, idleApp)
+				mutex.Unlock()
+			}(app)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(idleApps, func(i, j int) bool {
+		if idleApps[i].Score != idleApps[j].Score {
+			return idleApps[i].Score > idleApps[j].Score
+		}
+		if idleApps[i].AddOnMonthlyCost != idleApps[j].AddOnMonthlyCost {
+			return idleApps[i].AddOnMonthlyCost > idleApps[j].AddOnMonthlyCost
+		}
+		return idleApps[i].App < idleApps[j].App
+	})
+	return idleApps, <-errChannel
+}
+
+//ScoreIdleApp Compute the idle signals of an application
+func ScoreIdleApp(app HerokuApp, formations []heroku.Formation, lastRelease *time.Time, domains []heroku.Domain, now time.Time, staleDays int) IdleApp {
+	idleApp := IdleApp{
+		App:          app.App.Name,
+		Organization: appOrganization(app.App),
+		LastRelease:  lastRelease,
+	}
+
+	if len(app.Dynos) == 0 {
+		idleApp.Signals = append(idleApp.Signals, IdleSignalNoRunningDynos)
+	}
+
+	var quantity int
+	for _, formation := range formations {
+		quantity += formation.Quantity
+	}
+	if quantity == 0 {
+		idleApp.Signals = append(idleApp.Signals, IdleSignalZeroFormation)
+	}
+
+	if lastRelease == nil || lastRelease.Before(now.AddDate(0, 0, -staleDays)) {
+		idleApp.Signals = append(idleApp.Signals, IdleSignalStaleRelease)
+	}
+
+	// an app without add-ons does not get the signal
+	freeOnly := len(app.AddOns) > 0
+	for _, addOn := range app.AddOns {
+		idleApp.AddOnMonthlyCost += AddOnMonthlyCost(addOn)
+		if !IsFreeAddOn(addOn) {
+			freeOnly = false
+		}
+	}
+	if freeOnly {
+		idleApp.Signals = append(idleApp.Signals, IdleSignalFreeAddOnsOnly)
+	}
+
+	customDomain := false
+	for _, domain := range domains {
+		if domain.Kind == DomainKindCustom {
+			customDomain = true
+			break
+		}
+	}
+	if !customDomain {
+		idleApp.Signals = append(idleApp.Signals, IdleSignalNoCustomDomain)
+	}
+
+	idleApp.Score = len(idleApp.Signals)
+	return idleApp
+}
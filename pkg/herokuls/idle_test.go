@@ -0,0 +1,41 @@
+package herokuls
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+// decodeAddOns add-ons from their API representation, billed_price is an anonymous struct
+func decodeAddOns(t *testing.T, data string) []heroku.AddOn {
+	var addOns []heroku.AddOn
+	if err := json.Unmarshal([]byte(data), &addOns); err != nil {
+		t.Fatal(err)
+	}
+	return addOns
+}
+
+func TestScoreIdleAppFreeAddOnsOnly(t *testing.T) {
+	now := time.Date(2020, 6, 30, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		addOns string
+		want   bool
+	}{
+		{name: "no add-on", addOns: `[]`, want: false},
+		{name: "free plan", addOns: `[{"plan":{"name":"papertrail:choklad"},"billed_price":{"cents":0}}]`, want: true},
+		{name: "hobby plan", addOns: `[{"plan":{"name":"heroku-postgresql:hobby-basic"},"billed_price":{"cents":900}}]`, want: true},
+		{name: "contract", addOns: `[{"plan":{"name":"heroku-postgresql:standard-0"},"billed_price":{"cents":0,"contract":true}}]`, want: false},
+		{name: "unknown price", addOns: `[{"plan":{"name":"heroku-redis:premium-0"}}]`, want: false},
+		{name: "free and paid", addOns: `[{"plan":{"name":"papertrail:choklad"},"billed_price":{"cents":0}},{"plan":{"name":"heroku-redis:premium-0"},"billed_price":{"cents":1500}}]`, want: false},
+	}
+	for _, test := range tests {
+		app := HerokuApp{AddOns: decodeAddOns(t, test.addOns)}
+		idleApp := ScoreIdleApp(app, nil, &now, nil, now, 365)
+		if got := stringInSlice(IdleSignalFreeAddOnsOnly, idleApp.Signals); got != test.want {
+			t.Errorf("%s: %s signal = %t, want %t", test.name, IdleSignalFreeAddOnsOnly, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package herokuls
+
+import (
+	"sort"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+//TeamInvoices Recent invoices of a team compared to the cost computed from the inventory
+type TeamInvoices struct {
+	Team              string           `json:"team"`
+	ComputedCost      float64          `json:"computed_monthly_cost"`
+	ComputedDynoUnits int              `json:"computed_dyno_units"`
+	Invoices          []InvoiceSummary `json:"invoices"`
+}
+
+//InvoiceSummary Charges of an invoice in $, Delta is Total minus the computed cost
+//Change is the percentage of evolution of Total since the previous invoice
+type InvoiceSummary struct {
+	Number      int     `json:"number"`
+	PeriodStart string  `json:"period_start"`
+	PeriodEnd   string  `json:"period_end"`
+	Total       float64 `json:"total"`
+	Platform    float64 `json:"platform"`
+	AddOns      float64 `json:"addons"`
+	Database    float64 `json:"database"`
+	DynoUnits   float64 `json:"dyno_units"`
+	Delta       float64 `json:"delta"`
+	Change      float64 `json:"change"`
+}
+
+//ListInvoices Compare the last invoices of every organization with the cost of the current inventory
+//TeamInvoiceList is used first, OrganizationInvoiceList for legacy organizations
+func (hls *HerokuListing) ListInvoices(herokuOrgs []HerokuOrganization, pricing DynoPricing, last int) ([]TeamInvoices, error) {
+	var teamsInvoices []TeamInvoices
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, len(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		wg.Add(1)
+		go func(org HerokuOrganization) {
+			defer wg.Done()
+			rl.Take()
+			invoices, err := hls.getInvoicesbyTeam(org.Name())
+			if err != nil {
+				errChannel <- err
+				return
+			}
+			teamInvoices := TeamInvoices{Team: org.Name()}
+			for _, app := range org.Apps {
+				dynoCost, addOnCost := AppMonthlyCost(app, pricing)
+				teamInvoices.ComputedCost += dynoCost + addOnCost
+				teamInvoices.ComputedDynoUnits += CountTotalDynoUnitByApp(CountDynoTypeByApp(app.Dynos), pricing.Units)
+			}
+			teamInvoices.Invoices = SummarizeInvoices(invoices, teamInvoices.ComputedCost, last)
+			mutex.Lock()
+			teamsInvoices = append(teamsInvoices, teamInvoices)
+			mutex.Unlock()
+		}(org)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(teamsInvoices, func(i, j int) bool {
+		return teamsInvoices[i].Team < teamsInvoices[j].Team
+	})
+	return teamsInvoices, <-errChannel
+}
+
+//getInvoicesbyTeam Invoices of a team, converted from the organization invoices when the team endpoint fails
+func (hls *HerokuListing) getInvoicesbyTeam(team string) ([]heroku.TeamInvoice, error) {
+	invoices, err := hls.Cli.TeamInvoiceList(hls.ctx, team, &heroku.ListRange{Field: "number"})
+	if err == nil {
+		return invoices, nil
+	}
+	orgInvoices, orgErr := hls.Cli.OrganizationInvoiceList(hls.ctx, team, &heroku.ListRange{Field: "number"})
+	if orgErr != nil {
+		return nil, err
+	}
+	for _, invoice := range orgInvoices {
+		invoices = append(invoices, heroku.TeamInvoice(invoice))
+	}
+	return invoices, nil
+}
+
+//SummarizeInvoices Keep the last invoices, most recent first, with their delta and change
+func SummarizeInvoices(invoices []heroku.TeamInvoice, computedCost float64, last int) []InvoiceSummary {
+	sorted := append([]heroku.TeamInvoice{}, invoices...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PeriodStart > sorted[j].PeriodStart
+	})
+	if last > 0 && len(sorted) > last+1 {
+		// keep one more invoice to compute the change of the oldest one
+		sorted = sorted[:last+1]
+	}
+
+	var summaries []InvoiceSummary
+	for i, invoice := range sorted {
+		if last > 0 && i == last {
+			break
+		}
+		summary := InvoiceSummary{
+			Number:      invoice.Number,
+			PeriodStart: invoice.PeriodStart,
+			PeriodEnd:   invoice.PeriodEnd,
+			Total:       centsToDollars(invoice.Total),
+			Platform:    centsToDollars(invoice.PlatformTotal),
+			AddOns:      centsToDollars(invoice.AddonsTotal),
+			Database:    centsToDollars(invoice.DatabaseTotal),
+			DynoUnits:   invoice.DynoUnits,
+		}
+		summary.Delta = summary.Total - computedCost
+		if i+1 < len(sorted) && sorted[i+1].Total != 0 {
+			summary.Change = float64(invoice.Total-sorted[i+1].Total) / float64(sorted[i+1].Total) * 100
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
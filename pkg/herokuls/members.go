@@ -0,0 +1,168 @@
+package herokuls
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// MemberFlagNo2FA member without two factor authentication, federated members are exempted
+	MemberFlagNo2FA = "no-2fa"
+	// MemberFlagSingleTeam member of only one team
+	MemberFlagSingleTeam = "single-team"
+)
+
+//MembersReport Members of every team and pending invitations
+type MembersReport struct {
+	Members     []Member            `json:"members"`
+	Invitations []PendingInvitation `json:"pending_invitations"`
+}
+
+//Member A user and all his team memberships
+type Member struct {
+	Email                   string     `json:"email"`
+	Name                    string     `json:"name"`
+	Federated               bool       `json:"federated"`
+	TwoFactorAuthentication bool       `json:"two_factor_authentication"`
+	Teams                   []TeamRole `json:"teams"`
+	Flags                   []string   `json:"flags"`
+}
+
+//TeamRole Role of a member in a team
+type TeamRole struct {
+	Team string `json:"team"`
+	Role string `json:"role"`
+}
+
+//PendingInvitation Invitation to a team not yet accepted
+type PendingInvitation struct {
+	Team      string    `json:"team"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//ListTeamMembers Aggregate members and pending invitations of every team
+func (hls *HerokuListing) ListTeamMembers() (MembersReport, error) {
+	teams, err := hls.Cli.TeamList(hls.ctx, &heroku.ListRange{Field: "name"})
+	if err != nil {
+		return MembersReport{}, err
+	}
+
+	membersByEmail := make(map[string]*Member)
+	var invitations []PendingInvitation
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, 2*len(teams))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, team := range teams {
+		wg.Add(1)
+		go func(team heroku.Team) {
+			defer wg.Done()
+			rl.Take()
+			teamMembers, err := hls.Cli.TeamMemberList(hls.ctx, team.ID, &heroku.ListRange{Field: "email"})
+			if err != nil {
+				errChannel <- err
+			}
+			teamInvitations, err := hls.Cli.TeamInvitationList(hls.ctx, team.Name, &heroku.ListRange{Field: "id"})
+			if err != nil {
+				errChannel <- err
+			}
+			mutex.Lock()
+			for _, teamMember := range teamMembers {
+				member, ok := membersByEmail[teamMember.Email]
+				if !ok {
+					member = &Member{
+						Email:                   teamMember.Email,
+						Name:                    stringValue(teamMember.User.Name),
+						Federated:               teamMember.Federated,
+						TwoFactorAuthentication: teamMember.TwoFactorAuthentication,
+					}
+					membersByEmail[teamMember.Email] = member
+				}
+				member.Teams = append(member.Teams, TeamRole{
+					Team: team.Name,
+					Role: stringValue(teamMember.Role),
+				})
+			}
+			for _, invitation := range teamInvitations {
+				invitations = append(invitations, PendingInvitation{
+					Team:      team.Name,
+					Email:     invitation.User.Email,
+					Role:      stringValue(invitation.Role),
+					InvitedBy: invitation.InvitedBy.Email,
+					CreatedAt: invitation.CreatedAt,
+				})
+			}
+			mutex.Unlock()
+		}(team)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	var report MembersReport
+	for _, member := range membersByEmail {
+		sort.Slice(member.Teams, func(i, j int) bool {
+			return member.Teams[i].Team < member.Teams[j].Team
+		})
+		if member.lacks2FA() {
+			member.Flags = append(member.Flags, MemberFlagNo2FA)
+		}
+		if len(member.Teams) == 1 {
+			member.Flags = append(member.Flags, MemberFlagSingleTeam)
+		}
+		report.Members = append(report.Members, *member)
+	}
+	sort.Slice(report.Members, func(i, j int) bool {
+		return report.Members[i].Email < report.Members[j].Email
+	})
+	sort.Slice(invitations, func(i, j int) bool {
+		if invitations[i].Team != invitations[j].Team {
+			return invitations[i].Team < invitations[j].Team
+		}
+		return invitations[i].Email < invitations[j].Email
+	})
+	report.Invitations = invitations
+	return report, <-errChannel
+}
+
+//MembersWithout2FA Members who have not enabled two factor authentication
+//Federated members authenticate through the identity provider and are exempted
+func (r MembersReport) MembersWithout2FA() []Member {
+	var members []Member
+	for _, member := range r.Members {
+		if member.lacks2FA() {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// lacks2FA return true if the member logs in with heroku credentials without 2FA
+func (m Member) lacks2FA() bool {
+	return !m.Federated && !m.TwoFactorAuthentication
+}
+
+//Findings Members without 2FA are errors
+func (r MembersReport) Findings() findings.Report {
+	report := findings.NewReport("members",
+		findings.Rule{ID: MemberFlagNo2FA, Description: "team members must enable two factor authentication"},
+	)
+	for _, member := range r.MembersWithout2FA() {
+		var teams []string
+		for _, team := range member.Teams {
+			teams = append(teams, team.Team)
+		}
+		report.Add(MemberFlagNo2FA, findings.LevelError, member.Email, "2FA disabled, member of "+strings.Join(teams, ", "))
+	}
+	return report
+}
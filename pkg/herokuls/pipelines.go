@@ -0,0 +1,202 @@
+package herokuls
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// lastReleasesForPromotion number of releases by app searched for the last promotion
+	lastReleasesForPromotion = 20
+)
+
+//AppPipeline Pipeline and stage an application is coupled to
+type AppPipeline struct {
+	Pipeline string `json:"pipeline"`
+	Stage    string `json:"stage"`
+}
+
+//GetAppPipelines Pipeline coupling of every application, indexed by application ID
+func (hls *HerokuListing) GetAppPipelines() (map[string]AppPipeline, error) {
+	appPipelines := make(map[string]AppPipeline)
+	pipelines, err := hls.Cli.PipelineList(hls.ctx, &heroku.ListRange{Field: "name"})
+	if err != nil {
+		return appPipelines, err
+	}
+	pipelineNames := make(map[string]string, len(pipelines))
+	for _, pipeline := range pipelines {
+		pipelineNames[pipeline.ID] = pipeline.Name
+	}
+
+	couplings, err := hls.Cli.PipelineCouplingList(hls.ctx, &heroku.ListRange{Field: "id"})
+	if err != nil {
+		return appPipelines, err
+	}
+	for _, coupling := range couplings {
+		appPipelines[coupling.App.ID] = AppPipeline{
+			Pipeline: pipelineNames[coupling.Pipeline.ID],
+			Stage:    coupling.Stage,
+		}
+	}
+	return appPipelines, nil
+}
+
+//PipelineTopology Apps of a pipeline grouped by stage
+type PipelineTopology struct {
+	Pipeline                 string          `json:"pipeline"`
+	Stages                   []PipelineStage `json:"stages"`
+	LastPromotion            *time.Time      `json:"last_promotion,omitempty"`
+	LastPromotionDescription string          `json:"last_promotion_description,omitempty"`
+}
+
+//PipelineStage Apps coupled to a stage and the monthly cost of their dynos
+//Error is set when the releases of an app of the stage could not be listed, the last promotion may then be older
+type PipelineStage struct {
+	Stage    string   `json:"stage"`
+	Apps     []string `json:"applications"`
+	DynoCost float64  `json:"dyno_cost"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// PipelineStages Heroku pipeline stages from the first to the last one
+var PipelineStages = []string{"review", "development", "staging", "production"}
+
+//ListPipelines Map every coupled app of every pipeline to its stage
+//The last promotion is the most recent promote release among the last releases of the coupled apps
+func (hls *HerokuListing) ListPipelines(herokuOrgs []HerokuOrganization, pricing DynoPricing) ([]PipelineTopology, error) {
+	pipelines, err := hls.Cli.PipelineList(hls.ctx, &heroku.ListRange{Field: "name"})
+	if err != nil {
+		return []PipelineTopology{}, err
+	}
+	appsByID := make(map[string]HerokuApp, countApps(herokuOrgs))
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			appsByID[app.App.ID] = app
+		}
+	}
+
+	var topologies []PipelineTopology
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, len(pipelines))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, pipeline := range pipelines {
+		wg.Add(1)
+		go func(pipeline heroku.Pipeline) {
+			defer wg.Done()
+			rl.Take()
+			couplings, err := hls.Cli.PipelineCouplingListByPipeline(hls.ctx, pipeline.ID, &heroku.ListRange{Field: "id"})
+			if err != nil {
+				errChannel <- err
+				return
+			}
+			topology := BuildPipelineTopology(pipeline.Name, couplings, appsByID, pricing)
+			// the pipeline is kept when releases fail, the first error only is sent
+			var releasesErr error
+			for _, coupling := range couplings {
+				rl.Take()
+				releases, err := hls.Cli.ReleaseList(hls.ctx, coupling.App.ID, &heroku.ListRange{Field: "version", Max: lastReleasesForPromotion, Descending: true})
+				if err != nil {
+					topology.addStageError(coupling.Stage, appName(coupling.App.ID, appsByID)+": "+err.Error())
+					if releasesErr == nil {
+						releasesErr = err
+					}
+					continue
+				}
+				for i := range releases {
+					release := releases[i]
+					if !strings.HasPrefix(release.Description, releasePromotePrefix) {
+						continue
+					}
+					if topology.LastPromotion == nil || release.CreatedAt.After(*topology.LastPromotion) {
+						topology.LastPromotion = &release.CreatedAt
+						topology.LastPromotionDescription = release.App.Name + " " + release.Description
+					}
+					break
+				}
+			}
+			if releasesErr != nil {
+				errChannel <- releasesErr
+			}
+			mutex.Lock()
+			topologies = append(topologies, topology)
+			mutex.Unlock()
+		}(pipeline)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(topologies, func(i, j int) bool {
+		return topologies[i].Pipeline < topologies[j].Pipeline
+	})
+	return topologies, <-errChannel
+}
+
+//BuildPipelineTopology Group coupled apps by stage, in the PipelineStages order
+//Apps missing from the listing are named by their ID and cost nothing
+func BuildPipelineTopology(pipeline string, couplings []heroku.PipelineCoupling, appsByID map[string]HerokuApp, pricing DynoPricing) PipelineTopology {
+	stagesByName := make(map[string]*PipelineStage)
+	for _, coupling := range couplings {
+		stage, ok := stagesByName[coupling.Stage]
+		if !ok {
+			stage = &PipelineStage{Stage: coupling.Stage}
+			stagesByName[coupling.Stage] = stage
+		}
+		stage.Apps = append(stage.Apps, appName(coupling.App.ID, appsByID))
+		app, ok := appsByID[coupling.App.ID]
+		if !ok {
+			continue
+		}
+		dynoCost, _ := AppMonthlyCost(app, pricing)
+		stage.DynoCost += dynoCost
+	}
+
+	topology := PipelineTopology{Pipeline: pipeline}
+	for _, stage := range stagesByName {
+		sort.Strings(stage.Apps)
+		topology.Stages = append(topology.Stages, *stage)
+	}
+	sort.Slice(topology.Stages, func(i, j int) bool {
+		return stageIndex(topology.Stages[i].Stage) < stageIndex(topology.Stages[j].Stage)
+	})
+	return topology
+}
+
+// addStageError Append an error to the stage, errors of several apps are separated by "; "
+func (topology *PipelineTopology) addStageError(stageName, message string) {
+	for i := range topology.Stages {
+		if topology.Stages[i].Stage != stageName {
+			continue
+		}
+		if topology.Stages[i].Error != "" {
+			topology.Stages[i].Error += "; "
+		}
+		topology.Stages[i].Error += message
+		return
+	}
+}
+
+// appName Name of the app in the listing, its ID when it is missing
+func appName(appID string, appsByID map[string]HerokuApp) string {
+	if app, ok := appsByID[appID]; ok {
+		return app.App.Name
+	}
+	return appID
+}
+
+//stageIndex Position of the stage in PipelineStages, unknown stages come last
+func stageIndex(stage string) int {
+	for i, name := range PipelineStages {
+		if name == stage {
+			return i
+		}
+	}
+	return len(PipelineStages)
+}
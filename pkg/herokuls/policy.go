@@ -0,0 +1,264 @@
+package herokuls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// PolicyNoMatchingApps reason for a rule whose selector matches no application
+const PolicyNoMatchingApps = "no matching apps"
+
+//PolicyConfig Rules every application of the inventory is evaluated against
+type PolicyConfig struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+//PolicyRule Checks applied to the applications matching the selector
+//Every check set on the rule must pass for an application to comply
+type PolicyRule struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Match       PolicySelector `yaml:"match"`
+
+	// MinDynos minimum number of running dynos by process type, dynos which are not up do not count
+	MinDynos map[string]int `yaml:"min_dynos"`
+	// ForbiddenDynoSizes dyno sizes no dyno may run on, case insensitive
+	ForbiddenDynoSizes []string `yaml:"forbidden_dyno_sizes"`
+	// RequiredAddOns add-on services the app must use at least one of, owned or attached
+	RequiredAddOns []string `yaml:"required_addons"`
+	// ForbiddenStacks stacks the app may not run on
+	ForbiddenStacks []string `yaml:"forbidden_stacks"`
+	// RequiredFeatures app features which must all be enabled
+	RequiredFeatures []string `yaml:"required_features"`
+}
+
+//PolicySelector Applications a rule applies to, regular expressions must all match
+//An empty expression or environment list match everything, LoadPolicyConfig normalizes the environments
+type PolicySelector struct {
+	App          string   `yaml:"app"`
+	Organization string   `yaml:"organization"`
+	Environments []string `yaml:"environments"`
+
+	app          *regexp.Regexp
+	organization *regexp.Regexp
+}
+
+//PolicyResult Outcome of a rule over the inventory
+//A rule matching no application passes with Evaluated at 0, see UnmatchedPolicies
+type PolicyResult struct {
+	Rule        string            `json:"rule"`
+	Description string            `json:"description"`
+	Passed      bool              `json:"passed"`
+	Evaluated   int               `json:"evaluated"`
+	Violations  []PolicyViolation `json:"violations"`
+}
+
+//PolicyViolation Application failing a rule and why
+type PolicyViolation struct {
+	App          string   `json:"application"`
+	Organization string   `json:"organization"`
+	Reasons      []string `json:"reasons"`
+}
+
+//LoadPolicyConfig Read, compile and validate a yaml policy file
+func LoadPolicyConfig(r io.Reader) (PolicyConfig, error) {
+	var config PolicyConfig
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return config, err
+	}
+	if len(config.Rules) == 0 {
+		return config, errors.New("policy file has no rule")
+	}
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.Name == "" {
+			return config, fmt.Errorf("policy rule %d has no name", i+1)
+		}
+		if len(rule.MinDynos) == 0 && len(rule.ForbiddenDynoSizes) == 0 && len(rule.RequiredAddOns) == 0 && len(rule.ForbiddenStacks) == 0 && len(rule.RequiredFeatures) == 0 {
+			return config, fmt.Errorf("policy rule %s has no check", rule.Name)
+		}
+		// selectors are compared with classified environments, "prod" selects production apps
+		rule.Match.Environments = DefaultEnvironmentClassifier().NormalizeEnvironments(rule.Match.Environments)
+		if rule.Match.app, err = compileOptional(rule.Match.App); err != nil {
+			return config, err
+		}
+		if rule.Match.organization, err = compileOptional(rule.Match.Organization); err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+//UsesFeatures return true if a rule needs the features of the applications
+func (c PolicyConfig) UsesFeatures() bool {
+	for _, rule := range c.Rules {
+		if len(rule.RequiredFeatures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+//Matches return true if the application is selected
+func (s PolicySelector) Matches(app HerokuApp, organization string) bool {
+	if len(s.Environments) > 0 && !stringInSlice(app.Environment, s.Environments) {
+		return false
+	}
+	return matchOptional(s.app, app.App.Name) && matchOptional(s.organization, organization)
+}
+
+//EvaluatePolicy Evaluate every rule against every application of the inventory
+//Environment selectors need ClassifyEnvironments and required features LoadAppFeatures to be called first
+func EvaluatePolicy(herokuOrgs []HerokuOrganization, config PolicyConfig) []PolicyResult {
+	var results []PolicyResult
+	for _, rule := range config.Rules {
+		result := PolicyResult{
+			Rule:        rule.Name,
+			Description: rule.Description,
+		}
+		for _, org := range herokuOrgs {
+			for _, app := range org.Apps {
+				if !rule.Match.Matches(app, org.Name()) {
+					continue
+				}
+				result.Evaluated++
+				if reasons := rule.Check(app); len(reasons) > 0 {
+					result.Violations = append(result.Violations, PolicyViolation{
+						App:          app.App.Name,
+						Organization: org.Name(),
+						Reasons:      reasons,
+					})
+				}
+			}
+		}
+		sort.Slice(result.Violations, func(i, j int) bool {
+			return result.Violations[i].App < result.Violations[j].App
+		})
+		result.Passed = len(result.Violations) == 0
+		results = append(results, result)
+	}
+	return results
+}
+
+//Check Reasons the application fails the rule, empty when it complies
+func (r PolicyRule) Check(app HerokuApp) []string {
+	var reasons []string
+
+	var processTypes []string
+	for processType := range r.MinDynos {
+		processTypes = append(processTypes, processType)
+	}
+	sort.Strings(processTypes)
+	for _, processType := range processTypes {
+		var running int
+		for _, dyno := range app.Dynos {
+			if dyno.Type == processType && dyno.State == DynoStateUp {
+				running++
+			}
+		}
+		if running < r.MinDynos[processType] {
+			reasons = append(reasons, fmt.Sprintf("%d %s dyno(s), %d required", running, processType, r.MinDynos[processType]))
+		}
+	}
+
+	forbiddenSizes := make(map[string]bool)
+	for _, dyno := range app.Dynos {
+		for _, size := range r.ForbiddenDynoSizes {
+			if strings.EqualFold(dyno.Size, size) {
+				forbiddenSizes[dyno.Size] = true
+			}
+		}
+	}
+	for _, size := range sortedKeys(forbiddenSizes) {
+		reasons = append(reasons, "runs "+size+" dynos")
+	}
+
+	if len(r.RequiredAddOns) > 0 && !usesAddOnService(app, r.RequiredAddOns) {
+		reasons = append(reasons, "no "+strings.Join(r.RequiredAddOns, "/")+" add-on")
+	}
+
+	if stringInSlice(app.App.Stack.Name, r.ForbiddenStacks) {
+		reasons = append(reasons, "runs on stack "+app.App.Stack.Name)
+	}
+
+	for _, feature := range r.RequiredFeatures {
+		if !stringInSlice(feature, app.Features) {
+			reasons = append(reasons, "feature "+feature+" disabled")
+		}
+	}
+	return reasons
+}
+
+//FailedPolicies Results of the rules with at least one violation
+func FailedPolicies(results []PolicyResult) []PolicyResult {
+	var failed []PolicyResult
+	for _, result := range results {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+//UnmatchedPolicies Results of the rules whose selector matches no application, often a typo in the selector
+func UnmatchedPolicies(results []PolicyResult) []PolicyResult {
+	var unmatched []PolicyResult
+	for _, result := range results {
+		if result.Evaluated == 0 {
+			unmatched = append(unmatched, result)
+		}
+	}
+	return unmatched
+}
+
+//PolicyFindings One rule by policy rule, one error by offending application
+//A rule matching no application is a warning
+func PolicyFindings(results []PolicyResult) findings.Report {
+	report := findings.NewReport("policy")
+	for _, result := range results {
+		report.AddRule(result.Rule, result.Description)
+		if result.Evaluated == 0 {
+			report.Add(result.Rule, findings.LevelWarning, result.Rule, PolicyNoMatchingApps+", check the match selector")
+		}
+		for _, violation := range result.Violations {
+			report.Add(result.Rule, findings.LevelError, violation.Organization+"/"+violation.App, strings.Join(violation.Reasons, ", "))
+		}
+	}
+	return report
+}
+
+// usesAddOnService return true if one of the owned or attached add-ons is one of the services
+func usesAddOnService(app HerokuApp, services []string) bool {
+	for _, addOn := range app.AddOns {
+		if stringInSlice(addOn.AddonService.Name, services) {
+			return true
+		}
+	}
+	for _, attached := range app.AttachedAddOns {
+		if stringInSlice(attached.Service, services) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,159 @@
+package herokuls
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+)
+
+const testPolicy = `
+rules:
+  - name: web-redundancy
+    description: production web runs on two dynos at least
+    match:
+      environments: [prod]
+    min_dynos:
+      web: 2
+  - name: no-free-dynos
+    match:
+      app: "^shop-"
+    forbidden_dyno_sizes: [free]
+  - name: papertrail
+    match:
+      organization: "^acme$"
+    required_addons: [papertrail]
+  - name: modern-stack
+    forbidden_stacks: [heroku-16]
+  - name: runtime-metrics
+    match:
+      environments: [production]
+    required_features: [runtime-dyno-metadata, log-runtime-metrics]
+  - name: typo
+    match:
+      app: "^shpo-"
+    forbidden_stacks: [heroku-16]
+`
+
+// policyApp app of the acme organization, the OrganizationApp stack is an anonymous struct
+func policyApp(t *testing.T, name, stack, environment string, dynos ...heroku.Dyno) HerokuApp {
+	var app heroku.OrganizationApp
+	if err := json.Unmarshal([]byte(`{"name":"`+name+`","stack":{"name":"`+stack+`"}}`), &app); err != nil {
+		t.Fatal(err)
+	}
+	return HerokuApp{App: app, Environment: environment, Dynos: dynos}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	config, err := LoadPolicyConfig(strings.NewReader(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	web := heroku.Dyno{Type: "web", Size: "standard-1X", State: DynoStateUp}
+	crashed := heroku.Dyno{Type: "web", Size: "standard-1X", State: "crashed"}
+	free := heroku.Dyno{Type: "web", Size: "Free", State: DynoStateUp}
+
+	production := policyApp(t, "shop-production", "heroku-20", EnvironmentProduction, web, crashed)
+	production.Features = []string{"runtime-dyno-metadata"}
+	production.AttachedAddOns = []AttachedAddOn{{Name: "papertrail-shared", Service: "papertrail"}}
+	staging := policyApp(t, "shop-staging", "heroku-16", EnvironmentStaging, free)
+	herokuOrgs := []HerokuOrganization{
+		{org: heroku.Organization{Name: "acme"}, Apps: []HerokuApp{production, staging}},
+		{org: heroku.Organization{Name: "other"}, Apps: []HerokuApp{policyApp(t, "blog", "heroku-20", EnvironmentProduction, web, web)}},
+	}
+
+	want := map[string]struct {
+		evaluated  int
+		violations map[string][]string
+	}{
+		// the crashed dyno does not count, "prod" selects production apps
+		"web-redundancy": {evaluated: 2, violations: map[string][]string{"shop-production": {"1 web dyno(s), 2 required"}}},
+		"no-free-dynos":  {evaluated: 2, violations: map[string][]string{"shop-staging": {"runs Free dynos"}}},
+		"papertrail":     {evaluated: 2, violations: map[string][]string{"shop-staging": {"no papertrail add-on"}}},
+		"modern-stack":   {evaluated: 3, violations: map[string][]string{"shop-staging": {"runs on stack heroku-16"}}},
+		"runtime-metrics": {evaluated: 2, violations: map[string][]string{
+			"shop-production": {"feature log-runtime-metrics disabled"},
+			"blog":            {"feature runtime-dyno-metadata disabled", "feature log-runtime-metrics disabled"},
+		}},
+		"typo": {evaluated: 0, violations: map[string][]string{}},
+	}
+
+	results := EvaluatePolicy(herokuOrgs, config)
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for _, result := range results {
+		wantResult := want[result.Rule]
+		if result.Evaluated != wantResult.evaluated {
+			t.Errorf("%s: Evaluated = %d, want %d", result.Rule, result.Evaluated, wantResult.evaluated)
+		}
+		violations := make(map[string][]string)
+		for _, violation := range result.Violations {
+			violations[violation.App] = violation.Reasons
+		}
+		if !reflect.DeepEqual(violations, wantResult.violations) {
+			t.Errorf("%s: violations = %v, want %v", result.Rule, violations, wantResult.violations)
+		}
+		if result.Passed != (len(wantResult.violations) == 0) {
+			t.Errorf("%s: Passed = %t", result.Rule, result.Passed)
+		}
+	}
+
+	if unmatched := UnmatchedPolicies(results); len(unmatched) != 1 || unmatched[0].Rule != "typo" {
+		t.Errorf("UnmatchedPolicies = %+v, want the typo rule", unmatched)
+	}
+	if failed := FailedPolicies(results); len(failed) != 5 {
+		t.Errorf("FailedPolicies = %d, want 5", len(failed))
+	}
+
+	report := PolicyFindings(results)
+	if got := report.ByRule("typo"); len(got) != 1 || got[0].Level != findings.LevelWarning {
+		t.Errorf("typo findings = %+v, want one warning", got)
+	}
+	if got := report.Errors(); got != 6 {
+		t.Errorf("PolicyFindings errors = %d, want 6", got)
+	}
+}
+
+func TestPolicySelectorMatches(t *testing.T) {
+	config, err := LoadPolicyConfig(strings.NewReader(`
+rules:
+  - name: selected
+    match:
+      app: "-api$"
+      organization: "^acme"
+      environments: [" STG ", qa]
+    forbidden_stacks: [heroku-16]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector := config.Rules[0].Match
+	tests := []struct {
+		app          string
+		organization string
+		environment  string
+		want         bool
+	}{
+		{app: "shop-api", organization: "acme", environment: EnvironmentStaging, want: true},
+		{app: "shop-api", organization: "acme-eu", environment: EnvironmentTest, want: true},
+		{app: "shop-web", organization: "acme", environment: EnvironmentStaging},
+		{app: "shop-api", organization: "other", environment: EnvironmentStaging},
+		{app: "shop-api", organization: "acme", environment: EnvironmentProduction},
+		{app: "shop-api", organization: "acme", environment: EnvironmentUnknown},
+	}
+	for _, test := range tests {
+		app := HerokuApp{App: heroku.OrganizationApp{Name: test.app}, Environment: test.environment}
+		if got := selector.Matches(app, test.organization); got != test.want {
+			t.Errorf("Matches(%s, %s, %s) = %t, want %t", test.app, test.organization, test.environment, got, test.want)
+		}
+	}
+
+	if got := (PolicySelector{}).Matches(HerokuApp{}, ""); !got {
+		t.Errorf("empty selector does not match every app")
+	}
+}
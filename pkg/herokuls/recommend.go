@@ -0,0 +1,203 @@
+package herokuls
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// RecommendRulePerformanceNonProduction performance dynos on a non production app
+	RecommendRulePerformanceNonProduction = "performance-on-non-production"
+	// RecommendRuleConsolidate many standard dynos which could be fewer larger ones
+	RecommendRuleConsolidate = "consolidate-standard-dynos"
+	// RecommendRuleIdleWorker worker scaled up without any dyno, or with idle dynos only
+	RecommendRuleIdleWorker = "idle-worker"
+
+	// DynoStateUp Dyno.State of a running dyno
+	DynoStateUp = "up"
+	// DynoStateIdle Dyno.State of a dyno sleeping, crashed, starting or restarting dynos are not idle
+	DynoStateIdle = "idle"
+	// ProcessTypeWeb Formation.Type of the web process
+	ProcessTypeWeb = "web"
+
+	performanceSizePrefix = "performance"
+	standardSizePrefix    = "standard"
+)
+
+//RecommendRules Configurable thresholds of the rightsizing rules
+type RecommendRules struct {
+	NonProductionPattern string             `yaml:"non_production_pattern"`
+	NonProductionSize    string             `yaml:"non_production_size"`
+	MaxStandardDynos     int                `yaml:"max_standard_dynos"`
+	ConsolidateSize      string             `yaml:"consolidate_size"`
+	ConsolidateRatio     int                `yaml:"consolidate_ratio"`
+	IdleWorkers          bool               `yaml:"idle_workers"`
+	Prices               map[string]float64 `yaml:"prices"`
+}
+
+//Recommendation Change of the formation of one process type and its estimated savings
+type Recommendation struct {
+	App            string  `json:"application"`
+	Organization   string  `json:"organization"`
+	ProcessType    string  `json:"process_type"`
+	Rule           string  `json:"rule"`
+	Current        string  `json:"current"`
+	Suggested      string  `json:"suggested"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	SavingsUnknown bool    `json:"savings_unknown"`
+}
+
+//DefaultRecommendRules Rules used when no rule file is provided
+//Consolidation is disabled, a performance dyno costs as many dyno units as the standard dynos it replaces
+//and only saves money with negotiated prices, set consolidate_ratio and prices in a rule file to enable it
+func DefaultRecommendRules() RecommendRules {
+	return RecommendRules{
+		NonProductionPattern: `(?i)-(dev|development|staging|stage|qa|test|review|pr-[0-9]+)$`,
+		NonProductionSize:    "standard-2X",
+		MaxStandardDynos:     4,
+		ConsolidateSize:      "performance-M",
+		IdleWorkers:          true,
+	}
+}
+
+//LoadRecommendRules Read a yaml rule file, missing keys keep their default value
+func LoadRecommendRules(r io.Reader) (RecommendRules, error) {
+	rules := DefaultRecommendRules()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return rules, err
+	}
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return rules, err
+	}
+	return rules, nil
+}
+
+//ListRecommendations Apply the rightsizing rules to the formation of every application
+//The classified environment of an app wins over NonProductionPattern
+func (hls *HerokuListing) ListRecommendations(herokuOrgs []HerokuOrganization, pricing DynoPricing, rules RecommendRules) ([]Recommendation, error) {
+	nonProduction, err := regexp.Compile(rules.NonProductionPattern)
+	if err != nil {
+		return []Recommendation{}, err
+	}
+
+	var recommendations []Recommendation
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app HerokuApp) {
+				defer wg.Done()
+				rl.Take()
+				formations, err := hls.Cli.FormationList(hls.ctx, app.App.ID, &heroku.ListRange{Field: "id"})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				isNonProduction := nonProduction.MatchString(app.App.Name)
+				if app.Environment != "" && app.Environment != EnvironmentUnknown {
+					isNonProduction = app.Environment != EnvironmentProduction
+				}
+				appRecommendations := RecommendFormation(app, formations, isNonProduction, pricing, rules)
+				mutex.Lock()
+				recommendations = append(recommendations, appRecommendations...)
+				mutex.Unlock()
+			}(app)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].MonthlySavings != recommendations[j].MonthlySavings {
+			return recommendations[i].MonthlySavings > recommendations[j].MonthlySavings
+		}
+		return recommendations[i].App < recommendations[j].App
+	})
+	return recommendations, <-errChannel
+}
+
+//RecommendFormation Apply the rightsizing rules to the formation of one application
+//Recommendations without savings are dropped, they are kept with SavingsUnknown when a size has no known price
+func RecommendFormation(app HerokuApp, formations []heroku.Formation, nonProduction bool, pricing DynoPricing, rules RecommendRules) []Recommendation {
+	var recommendations []Recommendation
+	for _, formation := range formations {
+		if formation.Quantity == 0 {
+			continue
+		}
+		current := formatFormation(formation.Quantity, formation.Size)
+		currentPrice := float64(formation.Quantity) * pricing.MonthlyPrice(formation.Size)
+		recommendation := Recommendation{
+			App:          app.App.Name,
+			Organization: appOrganization(app.App),
+			ProcessType:  formation.Type,
+			Current:      current,
+		}
+		size := strings.ToLower(formation.Size)
+
+		switch {
+		case rules.IdleWorkers && formation.Type != ProcessTypeWeb && allDynosIdle(app.Dynos, formation.Type):
+			recommendation.Rule = RecommendRuleIdleWorker
+			recommendation.Suggested = formatFormation(0, formation.Size)
+			recommendation.MonthlySavings = currentPrice
+			recommendation.SavingsUnknown = !pricing.HasPrice(formation.Size)
+		case nonProduction && strings.HasPrefix(size, performanceSizePrefix) && rules.NonProductionSize != "":
+			recommendation.Rule = RecommendRulePerformanceNonProduction
+			recommendation.Suggested = formatFormation(formation.Quantity, rules.NonProductionSize)
+			recommendation.MonthlySavings = currentPrice - float64(formation.Quantity)*pricing.MonthlyPrice(rules.NonProductionSize)
+			recommendation.SavingsUnknown = !pricing.HasPrice(formation.Size) || !pricing.HasPrice(rules.NonProductionSize)
+		case strings.HasPrefix(size, standardSizePrefix) && rules.MaxStandardDynos > 0 && formation.Quantity > rules.MaxStandardDynos && rules.ConsolidateRatio > 0:
+			quantity := (formation.Quantity + rules.ConsolidateRatio - 1) / rules.ConsolidateRatio
+			recommendation.Rule = RecommendRuleConsolidate
+			recommendation.Suggested = formatFormation(quantity, rules.ConsolidateSize)
+			recommendation.MonthlySavings = currentPrice - float64(quantity)*pricing.MonthlyPrice(rules.ConsolidateSize)
+			recommendation.SavingsUnknown = !pricing.HasPrice(formation.Size) || !pricing.HasPrice(rules.ConsolidateSize)
+		default:
+			continue
+		}
+		if recommendation.SavingsUnknown {
+			recommendation.MonthlySavings = 0
+		} else if recommendation.MonthlySavings <= 0 {
+			continue
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations
+}
+
+// allDynosIdle true when no dyno of the process type exists or all of them are idle
+func allDynosIdle(dynos []heroku.Dyno, processType string) bool {
+	for _, dyno := range dynos {
+		if dyno.Type == processType && dyno.State != DynoStateIdle {
+			return false
+		}
+	}
+	return true
+}
+
+func formatFormation(quantity int, size string) string {
+	return fmt.Sprintf("%d x %s", quantity, size)
+}
+
+//TotalSavings Sum of the monthly savings of the recommendations, unknown savings are not counted
+func TotalSavings(recommendations []Recommendation) float64 {
+	var total float64
+	for _, recommendation := range recommendations {
+		total += recommendation.MonthlySavings
+	}
+	return total
+}
@@ -0,0 +1,56 @@
+package herokuls
+
+import (
+	"testing"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+func TestRecommendFormation(t *testing.T) {
+	priced := DynoPricing{Units: map[string]int{"standard-1X": 1, "standard-2X": 2, "performance-M": 8}, UnitPrice: 25}
+	unpriced := DynoPricing{Units: priced.Units}
+	rules := DefaultRecommendRules()
+
+	tests := []struct {
+		name          string
+		formation     heroku.Formation
+		dynoStates    []string
+		nonProduction bool
+		pricing       DynoPricing
+		wantRule      string
+		wantSavings   float64
+		wantUnknown   bool
+	}{
+		{name: "worker without dyno", formation: heroku.Formation{Type: "worker", Quantity: 2, Size: "standard-1X"}, pricing: priced, wantRule: RecommendRuleIdleWorker, wantSavings: 50},
+		{name: "worker with idle dynos", formation: heroku.Formation{Type: "worker", Quantity: 1, Size: "standard-1X"}, dynoStates: []string{DynoStateIdle}, pricing: priced, wantRule: RecommendRuleIdleWorker, wantSavings: 25},
+		{name: "worker up", formation: heroku.Formation{Type: "worker", Quantity: 1, Size: "standard-1X"}, dynoStates: []string{DynoStateUp}, pricing: priced},
+		{name: "worker crashed", formation: heroku.Formation{Type: "worker", Quantity: 1, Size: "standard-1X"}, dynoStates: []string{"crashed"}, pricing: priced},
+		{name: "worker starting", formation: heroku.Formation{Type: "worker", Quantity: 2, Size: "standard-1X"}, dynoStates: []string{DynoStateIdle, "starting"}, pricing: priced},
+		{name: "worker scaled to 0", formation: heroku.Formation{Type: "worker", Quantity: 0, Size: "standard-1X"}, pricing: priced},
+		{name: "performance on non production", formation: heroku.Formation{Type: ProcessTypeWeb, Quantity: 1, Size: "performance-M"}, dynoStates: []string{DynoStateUp}, nonProduction: true, pricing: priced, wantRule: RecommendRulePerformanceNonProduction, wantSavings: 150},
+		{name: "performance on production", formation: heroku.Formation{Type: ProcessTypeWeb, Quantity: 1, Size: "performance-M"}, dynoStates: []string{DynoStateUp}, pricing: priced},
+		{name: "unknown price", formation: heroku.Formation{Type: ProcessTypeWeb, Quantity: 1, Size: "performance-M"}, dynoStates: []string{DynoStateUp}, nonProduction: true, pricing: unpriced, wantRule: RecommendRulePerformanceNonProduction, wantUnknown: true},
+	}
+	for _, test := range tests {
+		app := HerokuApp{App: heroku.OrganizationApp{Name: "app"}}
+		for _, state := range test.dynoStates {
+			app.Dynos = append(app.Dynos, heroku.Dyno{Type: test.formation.Type, Size: test.formation.Size, State: state})
+		}
+		recommendations := RecommendFormation(app, []heroku.Formation{test.formation}, test.nonProduction, test.pricing, rules)
+		if test.wantRule == "" {
+			if len(recommendations) != 0 {
+				t.Errorf("%s: got %+v, want no recommendation", test.name, recommendations)
+			}
+			continue
+		}
+		if len(recommendations) != 1 {
+			t.Errorf("%s: got %d recommendations, want 1", test.name, len(recommendations))
+			continue
+		}
+		recommendation := recommendations[0]
+		if recommendation.Rule != test.wantRule || recommendation.MonthlySavings != test.wantSavings || recommendation.SavingsUnknown != test.wantUnknown {
+			t.Errorf("%s: got rule %s, savings %.2f, unknown %t, want %s, %.2f, %t", test.name,
+				recommendation.Rule, recommendation.MonthlySavings, recommendation.SavingsUnknown, test.wantRule, test.wantSavings, test.wantUnknown)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package herokuls
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// ReleaseFlagStale no release within ReleaseActivityOptions.StaleDays
+	ReleaseFlagStale = "stale"
+	// ReleaseFlagBusy more deploys a day than ReleaseActivityOptions.BusyPerDay
+	ReleaseFlagBusy = "busy"
+	// ReleaseFlagTruncated MaxReleases reached before the start of the window, counts only cover part of it
+	ReleaseFlagTruncated = "truncated"
+
+	// releasePageSize releases fetched by request
+	releasePageSize = 200
+
+	// Release.Description prefixes
+	releaseDeployPrefix   = "Deploy "
+	releasePromotePrefix  = "Promote "
+	releaseRollbackPrefix = "Rollback to "
+)
+
+//ReleaseActivityOptions How many releases are fetched and what is stale or busy
+//Releases are fetched newest first until one is older than the window, MaxReleases at most
+type ReleaseActivityOptions struct {
+	MaxReleases int
+	WindowDays  int
+	StaleDays   int
+	BusyPerDay  float64
+}
+
+//ReleaseActivity Deploy activity of an application
+type ReleaseActivity struct {
+	App           string     `json:"application"`
+	Organization  string     `json:"organization"`
+	Version       int        `json:"version"`
+	LastRelease   *time.Time `json:"last_release,omitempty"`
+	LastDeploy    *time.Time `json:"last_deploy,omitempty"`
+	LastDeployer  string     `json:"last_deployer"`
+	Releases      int        `json:"releases_in_window"`
+	Deploys       int        `json:"deploys_in_window"`
+	Rollbacks     int        `json:"rollbacks_in_window"`
+	DeploysPerDay float64    `json:"deploys_per_day"`
+	Flags         []string   `json:"flags"`
+}
+
+//ListReleaseActivity Summarize the recent releases of every application
+func (hls *HerokuListing) ListReleaseActivity(herokuOrgs []HerokuOrganization, opts ReleaseActivityOptions) ([]ReleaseActivity, error) {
+	var activities []ReleaseActivity
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+	now := time.Now()
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				releases, truncated, err := hls.listWindowReleases(app.ID, now, opts, rl)
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				activity := SummarizeReleases(releases, now, opts, truncated)
+				activity.App = app.Name
+				activity.Organization = appOrganization(app)
+				mutex.Lock()
+				activities = append(activities, activity)
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].App < activities[j].App
+	})
+	return activities, <-errChannel
+}
+
+// listWindowReleases Page releases by version, newest first, until one is older than the window
+// truncated is set when MaxReleases is reached before
+func (hls *HerokuListing) listWindowReleases(appID string, now time.Time, opts ReleaseActivityOptions, rl ratelimit.Limiter) ([]heroku.Release, bool, error) {
+	var releases []heroku.Release
+	windowStart := now.AddDate(0, 0, -opts.WindowDays)
+	listRange := &heroku.ListRange{Field: "version", Descending: true}
+	for {
+		listRange.Max = releasePageSize
+		if left := opts.MaxReleases - len(releases); left < listRange.Max {
+			listRange.Max = left
+		}
+		rl.Take()
+		page, err := hls.Cli.ReleaseList(hls.ctx, appID, listRange)
+		if err != nil {
+			return releases, false, err
+		}
+		releases = append(releases, page...)
+		if len(page) < listRange.Max {
+			return releases, false, nil
+		}
+		oldest := page[len(page)-1]
+		if oldest.CreatedAt.Before(windowStart) {
+			return releases, false, nil
+		}
+		if len(releases) >= opts.MaxReleases {
+			return releases, true, nil
+		}
+		// versions are unique, the next page starts right after the oldest one
+		listRange.FirstID = "]" + strconv.Itoa(oldest.Version)
+	}
+}
+
+//SummarizeReleases Compute the activity from releases, whatever their order
+//When truncated, the releases do not reach the start of the window and the deploy rate is computed on the span they cover
+func SummarizeReleases(releases []heroku.Release, now time.Time, opts ReleaseActivityOptions, truncated bool) ReleaseActivity {
+	var activity ReleaseActivity
+	windowStart := now.AddDate(0, 0, -opts.WindowDays)
+	windowDays := float64(opts.WindowDays)
+	var oldest *time.Time
+	for i := range releases {
+		release := releases[i]
+		if oldest == nil || release.CreatedAt.Before(*oldest) {
+			oldest = &release.CreatedAt
+		}
+		if release.Version > activity.Version {
+			activity.Version = release.Version
+		}
+		if activity.LastRelease == nil || release.CreatedAt.After(*activity.LastRelease) {
+			activity.LastRelease = &release.CreatedAt
+		}
+		isDeploy := strings.HasPrefix(release.Description, releaseDeployPrefix) || strings.HasPrefix(release.Description, releasePromotePrefix)
+		if isDeploy && (activity.LastDeploy == nil || release.CreatedAt.After(*activity.LastDeploy)) {
+			activity.LastDeploy = &release.CreatedAt
+			activity.LastDeployer = release.User.Email
+		}
+		if release.CreatedAt.Before(windowStart) {
+			continue
+		}
+		activity.Releases++
+		if isDeploy {
+			activity.Deploys++
+		}
+		if strings.HasPrefix(release.Description, releaseRollbackPrefix) {
+			activity.Rollbacks++
+		}
+	}
+	if truncated && oldest != nil && oldest.After(windowStart) {
+		activity.Flags = append(activity.Flags, ReleaseFlagTruncated)
+		// a day at least, a burst of releases within an hour is not spread over less
+		windowDays = math.Max(now.Sub(*oldest).Hours()/24, 1)
+	}
+	if windowDays > 0 {
+		activity.DeploysPerDay = float64(activity.Deploys) / windowDays
+	}
+	if activity.LastRelease == nil || activity.LastRelease.Before(now.AddDate(0, 0, -opts.StaleDays)) {
+		activity.Flags = append(activity.Flags, ReleaseFlagStale)
+	}
+	if opts.BusyPerDay > 0 && activity.DeploysPerDay > opts.BusyPerDay {
+		activity.Flags = append(activity.Flags, ReleaseFlagBusy)
+	}
+	return activity
+}
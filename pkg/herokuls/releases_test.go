@@ -0,0 +1,72 @@
+package herokuls
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+// hourlyDeploys releases from version first, one deploy an hour going back from now
+func hourlyDeploys(now time.Time, first, count int) []heroku.Release {
+	var releases []heroku.Release
+	for i := 0; i < count; i++ {
+		release := heroku.Release{
+			Version:     first - i,
+			CreatedAt:   now.Add(-time.Duration(i) * time.Hour),
+			Description: "Deploy abc123",
+		}
+		release.User.Email = "dev@example.com"
+		releases = append(releases, release)
+	}
+	return releases
+}
+
+func TestSummarizeReleases(t *testing.T) {
+	now := time.Date(2020, 6, 30, 12, 0, 0, 0, time.UTC)
+	opts := ReleaseActivityOptions{MaxReleases: 200, WindowDays: 30, StaleDays: 365, BusyPerDay: 10}
+
+	// 200 hourly deploys only cover the last 8 days of the 30 days window
+	partial := hourlyDeploys(now, 500, 200)
+	// the oldest release is before the window, the window is fully covered
+	complete := append(hourlyDeploys(now, 12, 10), heroku.Release{Version: 2, CreatedAt: now.AddDate(0, 0, -40), Description: "Rollback to v1"})
+	rollback := []heroku.Release{{Version: 3, CreatedAt: now.AddDate(0, 0, -1), Description: "Rollback to v1"}}
+	stale := []heroku.Release{{Version: 1, CreatedAt: now.AddDate(-2, 0, 0), Description: "Deploy abc123"}}
+
+	tests := []struct {
+		name          string
+		releases      []heroku.Release
+		truncated     bool
+		wantVersion   int
+		wantDeploys   int
+		wantRollbacks int
+		wantPerDay    float64
+		wantFlags     []string
+	}{
+		{name: "partly covered window", releases: partial, truncated: true, wantVersion: 500, wantDeploys: 200, wantPerDay: 200 / (199.0 / 24), wantFlags: []string{ReleaseFlagTruncated, ReleaseFlagBusy}},
+		{name: "partly covered window, not truncated", releases: partial, wantVersion: 500, wantDeploys: 200, wantPerDay: 200 / 30.0},
+		{name: "covered window", releases: complete, truncated: true, wantVersion: 12, wantDeploys: 10, wantPerDay: 10 / 30.0},
+		{name: "rollback", releases: rollback, wantVersion: 3, wantRollbacks: 1},
+		{name: "stale", releases: stale, wantVersion: 1, wantFlags: []string{ReleaseFlagStale}},
+		{name: "no release", wantFlags: []string{ReleaseFlagStale}},
+	}
+	for _, test := range tests {
+		activity := SummarizeReleases(test.releases, now, opts, test.truncated)
+		if activity.Version != test.wantVersion {
+			t.Errorf("%s: Version = %d, want %d", test.name, activity.Version, test.wantVersion)
+		}
+		if activity.Deploys != test.wantDeploys {
+			t.Errorf("%s: Deploys = %d, want %d", test.name, activity.Deploys, test.wantDeploys)
+		}
+		if activity.Rollbacks != test.wantRollbacks {
+			t.Errorf("%s: Rollbacks = %d, want %d", test.name, activity.Rollbacks, test.wantRollbacks)
+		}
+		if diff := activity.DeploysPerDay - test.wantPerDay; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: DeploysPerDay = %f, want %f", test.name, activity.DeploysPerDay, test.wantPerDay)
+		}
+		if !reflect.DeepEqual(activity.Flags, test.wantFlags) {
+			t.Errorf("%s: Flags = %v, want %v", test.name, activity.Flags, test.wantFlags)
+		}
+	}
+}
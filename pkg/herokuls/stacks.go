@@ -0,0 +1,197 @@
+package herokuls
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// StackStateDeprecated Stack.State of a deprecated stack
+	StackStateDeprecated = "deprecated"
+
+	// StackStatusSupported stack is neither deprecated nor close to its end of life
+	StackStatusSupported = "supported"
+	// StackStatusSoonEOL stack end of life is within the warning window
+	StackStatusSoonEOL = "soon-eol"
+	// StackStatusDeprecated stack is deprecated by heroku
+	StackStatusDeprecated = "deprecated"
+	// StackStatusEOL stack end of life is passed
+	StackStatusEOL = "eol"
+
+	// StackRuleUnsupported finding rule of apps on a deprecated or end of life stack
+	StackRuleUnsupported = "stack-unsupported"
+	// StackRuleSoonEOL finding rule of apps on a stack reaching end of life soon
+	StackRuleSoonEOL = "stack-soon-eol"
+
+	// eolDateFormat format of the dates in the EOL table
+	eolDateFormat = "2006-01-02"
+)
+
+// DefaultStackEOL End of life of heroku stacks, override it with an EOL file
+var DefaultStackEOL = map[string]time.Time{
+	"cedar-14":  time.Date(2019, time.April, 30, 0, 0, 0, 0, time.UTC),
+	"heroku-16": time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC),
+	"heroku-18": time.Date(2023, time.April, 30, 0, 0, 0, 0, time.UTC),
+	"heroku-20": time.Date(2025, time.April, 30, 0, 0, 0, 0, time.UTC),
+}
+
+//StackReport Usage of every stack and the stack status of every application
+type StackReport struct {
+	Stacks []StackUsage `json:"stacks"`
+	Apps   []StackApp   `json:"applications"`
+}
+
+//StackUsage Number of applications running on a stack
+type StackUsage struct {
+	Stack          string         `json:"stack"`
+	State          string         `json:"state"`
+	EOL            *time.Time     `json:"eol,omitempty"`
+	Status         string         `json:"status"`
+	Total          int            `json:"total"`
+	ByOrganization map[string]int `json:"by_organization"`
+}
+
+//StackApp Stack of an application
+type StackApp struct {
+	App          string     `json:"application"`
+	Organization string     `json:"organization"`
+	Stack        string     `json:"stack"`
+	Status       string     `json:"status"`
+	EOL          *time.Time `json:"eol,omitempty"`
+}
+
+//LoadStackEOL Read an EOL table, a yaml map of stack name to date (2006-01-02)
+func LoadStackEOL(r io.Reader) (map[string]time.Time, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var dates map[string]string
+	if err := yaml.Unmarshal(b, &dates); err != nil {
+		return nil, err
+	}
+	eol := make(map[string]time.Time, len(dates))
+	for stack, date := range dates {
+		t, err := time.Parse(eolDateFormat, date)
+		if err != nil {
+			return nil, err
+		}
+		eol[stack] = t
+	}
+	return eol, nil
+}
+
+//GetStackReport Group applications by stack using StackList for the stack state
+func (hls *HerokuListing) GetStackReport(herokuOrgs []HerokuOrganization, eol map[string]time.Time, warnDays int) (StackReport, error) {
+	stacks, err := hls.Cli.StackList(hls.ctx, &heroku.ListRange{Field: "name"})
+	if err != nil {
+		return StackReport{}, err
+	}
+	return BuildStackReport(herokuOrgs, stacks, eol, time.Now(), warnDays), nil
+}
+
+//BuildStackReport Group applications by stack and compute the status of every stack
+func BuildStackReport(herokuOrgs []HerokuOrganization, stacks []heroku.Stack, eol map[string]time.Time, now time.Time, warnDays int) StackReport {
+	usageByStack := make(map[string]*StackUsage)
+	for _, stack := range stacks {
+		usageByStack[stack.Name] = newStackUsage(stack.Name, stack.State, eol, now, warnDays)
+	}
+
+	var report StackReport
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			stackName := app.App.Stack.Name
+			usage, ok := usageByStack[stackName]
+			if !ok {
+				// Stack removed from StackList, only the EOL table knows about it
+				usage = newStackUsage(stackName, "", eol, now, warnDays)
+				usageByStack[stackName] = usage
+			}
+			organization := appOrganization(app.App)
+			usage.Total++
+			usage.ByOrganization[organization]++
+			report.Apps = append(report.Apps, StackApp{
+				App:          app.App.Name,
+				Organization: organization,
+				Stack:        stackName,
+				Status:       usage.Status,
+				EOL:          usage.EOL,
+			})
+		}
+	}
+
+	for _, usage := range usageByStack {
+		report.Stacks = append(report.Stacks, *usage)
+	}
+	sort.Slice(report.Stacks, func(i, j int) bool {
+		return report.Stacks[i].Stack < report.Stacks[j].Stack
+	})
+	sort.Slice(report.Apps, func(i, j int) bool {
+		if report.Apps[i].Stack != report.Apps[j].Stack {
+			return report.Apps[i].Stack < report.Apps[j].Stack
+		}
+		return report.Apps[i].App < report.Apps[j].App
+	})
+	return report
+}
+
+func newStackUsage(stack, state string, eol map[string]time.Time, now time.Time, warnDays int) *StackUsage {
+	usage := &StackUsage{
+		Stack:          stack,
+		State:          state,
+		Status:         StackStatusSupported,
+		ByOrganization: make(map[string]int),
+	}
+	if state == StackStateDeprecated {
+		usage.Status = StackStatusDeprecated
+	}
+	if date, ok := eol[stack]; ok {
+		usage.EOL = &date
+		if now.After(date) {
+			usage.Status = StackStatusEOL
+		} else if usage.Status == StackStatusSupported && now.AddDate(0, 0, warnDays).After(date) {
+			usage.Status = StackStatusSoonEOL
+		}
+	}
+	return usage
+}
+
+//AppsOnUnsupportedStack Applications whose stack is deprecated or past its end of life
+//Stacks close to their end of life are still supported
+func (r StackReport) AppsOnUnsupportedStack() []StackApp {
+	var apps []StackApp
+	for _, app := range r.Apps {
+		if app.Status == StackStatusDeprecated || app.Status == StackStatusEOL {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+//Findings Apps on deprecated or end of life stacks are errors, apps on stacks reaching end of life warnings
+//Errors are the apps of AppsOnUnsupportedStack
+func (r StackReport) Findings() findings.Report {
+	report := findings.NewReport("stacks",
+		findings.Rule{ID: StackRuleUnsupported, Description: "apps must not run on a deprecated or end of life stack"},
+		findings.Rule{ID: StackRuleSoonEOL, Description: "apps should leave stacks reaching end of life"},
+	)
+	for _, app := range r.Apps {
+		message := "runs on " + app.Stack + " (" + app.Status + ")"
+		if app.EOL != nil {
+			message += ", end of life " + app.EOL.Format(eolDateFormat)
+		}
+		switch app.Status {
+		case StackStatusDeprecated, StackStatusEOL:
+			report.Add(StackRuleUnsupported, findings.LevelError, app.Organization+"/"+app.App, message)
+		case StackStatusSoonEOL:
+			report.Add(StackRuleSoonEOL, findings.LevelWarning, app.Organization+"/"+app.App, message)
+		}
+	}
+	return report
+}
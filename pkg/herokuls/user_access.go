@@ -0,0 +1,256 @@
+package herokuls
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// AccessKindTeamMember user is a member of the team
+	AccessKindTeamMember = "team-member"
+	// AccessKindTeamApp user can access the app through his team membership
+	AccessKindTeamApp = "team-app"
+	// AccessKindAppOwner user owns the personal app
+	AccessKindAppOwner = "app-owner"
+	// AccessKindCollaborator user is a direct collaborator of the app, personal or team app
+	AccessKindCollaborator = "collaborator"
+	// AccessKindOAuthAuthorization OAuth authorization granted by the user
+	AccessKindOAuthAuthorization = "oauth-authorization"
+	// AccessKindSSHKey SSH key uploaded by the user
+	AccessKindSSHKey = "ssh-key"
+)
+
+//IdentityAccess Every access path tied to an email, used for offboarding
+type IdentityAccess struct {
+	Email string       `json:"email"`
+	Paths []AccessPath `json:"access_paths"`
+	Notes []string     `json:"notes,omitempty"`
+}
+
+//AccessPath One way an identity can access Heroku resources
+type AccessPath struct {
+	Kind   string `json:"kind"`
+	Team   string `json:"team,omitempty"`
+	App    string `json:"application,omitempty"`
+	Role   string `json:"role,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+//ListIdentityAccess Enumerate teams, apps, OAuth authorizations and SSH keys tied to an email
+//OAuth authorizations and SSH keys are only visible when the email is the authenticated account
+func (hls *HerokuListing) ListIdentityAccess(email string) (IdentityAccess, error) {
+	identity := IdentityAccess{Email: email}
+
+	teamPaths, err := hls.getTeamAccessPaths(email)
+	if err != nil {
+		return identity, err
+	}
+	identity.Paths = append(identity.Paths, teamPaths...)
+
+	// team apps reached through the membership are not listed again as collaborations
+	teamApps := make(map[string]bool)
+	for _, path := range teamPaths {
+		if path.Kind == AccessKindTeamApp {
+			teamApps[path.App] = true
+		}
+	}
+	appPaths, err := hls.getAppAccessPaths(email, teamApps)
+	if err != nil {
+		return identity, err
+	}
+	identity.Paths = append(identity.Paths, appPaths...)
+	sortAccessPaths(identity.Paths)
+
+	account, err := hls.Cli.AccountInfo(hls.ctx)
+	if err != nil {
+		return identity, err
+	}
+	if !strings.EqualFold(account.Email, email) {
+		identity.Notes = append(identity.Notes, "OAuth authorizations and SSH keys are only visible to "+email+" itself, run this command with its credentials to list them")
+		return identity, nil
+	}
+	accountPaths, err := hls.getAccountAccessPaths()
+	if err != nil {
+		return identity, err
+	}
+	identity.Paths = append(identity.Paths, accountPaths...)
+	return identity, nil
+}
+
+//getTeamAccessPaths Membership of the email in every team and the team apps it can access
+func (hls *HerokuListing) getTeamAccessPaths(email string) ([]AccessPath, error) {
+	teams, err := hls.Cli.TeamList(hls.ctx, &heroku.ListRange{Field: "name"})
+	if err != nil {
+		return []AccessPath{}, err
+	}
+
+	var paths []AccessPath
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, 2*len(teams))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, team := range teams {
+		wg.Add(1)
+		go func(team heroku.Team) {
+			defer wg.Done()
+			rl.Take()
+			teamMembers, err := hls.Cli.TeamMemberList(hls.ctx, team.ID, &heroku.ListRange{Field: "email"})
+			if err != nil {
+				errChannel <- err
+				return
+			}
+			var teamPaths []AccessPath
+			for _, teamMember := range teamMembers {
+				if !strings.EqualFold(teamMember.Email, email) {
+					continue
+				}
+				teamPaths = append(teamPaths, AccessPath{
+					Kind: AccessKindTeamMember,
+					Team: team.Name,
+					Role: stringValue(teamMember.Role),
+				})
+				memberApps, err := hls.Cli.TeamMemberListByMember(hls.ctx, team.ID, teamMember.Email, &heroku.ListRange{Field: "name"})
+				if err != nil {
+					errChannel <- err
+					break
+				}
+				for _, app := range memberApps {
+					teamPaths = append(teamPaths, AccessPath{
+						Kind: AccessKindTeamApp,
+						Team: team.Name,
+						App:  app.Name,
+					})
+				}
+			}
+			mutex.Lock()
+			paths = append(paths, teamPaths...)
+			mutex.Unlock()
+		}(team)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sortAccessPaths(paths)
+	return paths, <-errChannel
+}
+
+//getAppAccessPaths Personal apps the email owns and apps, personal or team, it collaborates on
+//Team apps in teamApps are already reached through the team membership and skipped
+func (hls *HerokuListing) getAppAccessPaths(email string, teamApps map[string]bool) ([]AccessPath, error) {
+	apps, err := hls.Cli.AppList(hls.ctx, &heroku.ListRange{Field: "name"})
+	if err != nil {
+		return []AccessPath{}, err
+	}
+
+	var paths []AccessPath
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, len(apps))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, app := range apps {
+		team := appTeam(app)
+		if team != "" && teamApps[app.Name] {
+			continue
+		}
+		if team == "" && strings.EqualFold(app.Owner.Email, email) {
+			mutex.Lock()
+			paths = append(paths, AccessPath{
+				Kind: AccessKindAppOwner,
+				App:  app.Name,
+			})
+			mutex.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(app heroku.App, team string) {
+			defer wg.Done()
+			rl.Take()
+			collaborators, err := hls.Cli.CollaboratorList(hls.ctx, app.ID, &heroku.ListRange{Field: "email"})
+			if err != nil {
+				errChannel <- err
+				return
+			}
+			for _, collaborator := range collaborators {
+				if strings.EqualFold(collaborator.User.Email, email) {
+					mutex.Lock()
+					paths = append(paths, AccessPath{
+						Kind: AccessKindCollaborator,
+						Team: team,
+						App:  app.Name,
+						Role: stringValue(collaborator.Role),
+					})
+					mutex.Unlock()
+				}
+			}
+		}(app, team)
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sortAccessPaths(paths)
+	return paths, <-errChannel
+}
+
+// appTeam name of the team owning the app, empty for personal apps
+func appTeam(app heroku.App) string {
+	if app.Team != nil {
+		return app.Team.Name
+	}
+	if app.Organization != nil {
+		return app.Organization.Name
+	}
+	return ""
+}
+
+//getAccountAccessPaths OAuth authorizations and SSH keys of the authenticated account
+func (hls *HerokuListing) getAccountAccessPaths() ([]AccessPath, error) {
+	var paths []AccessPath
+	authorizations, err := hls.Cli.OAuthAuthorizationList(hls.ctx, &heroku.ListRange{Field: "id"})
+	if err != nil {
+		return paths, err
+	}
+	for _, authorization := range authorizations {
+		client := "(no client)"
+		if authorization.Client != nil {
+			client = authorization.Client.Name
+		}
+		paths = append(paths, AccessPath{
+			Kind:   AccessKindOAuthAuthorization,
+			Role:   strings.Join(authorization.Scope, ","),
+			Detail: client + " created " + authorization.CreatedAt.Format("2006-01-02"),
+		})
+	}
+
+	keys, err := hls.Cli.KeyList(hls.ctx, &heroku.ListRange{Field: "id"})
+	if err != nil {
+		return paths, err
+	}
+	for _, key := range keys {
+		paths = append(paths, AccessPath{
+			Kind:   AccessKindSSHKey,
+			Detail: key.Comment + " " + key.Fingerprint,
+		})
+	}
+	return paths, nil
+}
+
+//sortAccessPaths Sort by team, the team membership comes before the team apps
+func sortAccessPaths(paths []AccessPath) {
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].Team != paths[j].Team {
+			return paths[i].Team < paths[j].Team
+		}
+		if paths[i].Kind != paths[j].Kind {
+			return paths[i].Kind > paths[j].Kind
+		}
+		return paths[i].App < paths[j].App
+	})
+}
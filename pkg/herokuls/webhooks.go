@@ -0,0 +1,235 @@
+package herokuls
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	heroku "github.com/heroku/heroku-go/v3"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	// WebhookFlagFailing the last deliveries of the webhook all failed
+	WebhookFlagFailing = "failing-deliveries"
+	// WebhookFlagUnknownHost webhook target is not on the host allowlist
+	WebhookFlagUnknownHost = "unknown-host"
+
+	// DeliveryStatusFailed AppWebhookDelivery.Status of a delivery which will not be retried
+	DeliveryStatusFailed = "failed"
+
+	// maxDeliveryPages pages of deliveries fetched at most by app
+	maxDeliveryPages = 10
+)
+
+//WebhookOptions Hosts webhooks may target and how many recent deliveries must fail to flag a webhook
+//MaxDeliveries is the number of deliveries fetched by request, the deliveries of an app are shared by its webhooks
+//so pages are fetched, following the Next-Range of the API, until every webhook has FailedDeliveries deliveries
+type WebhookOptions struct {
+	AllowedHosts     []string
+	FailedDeliveries int
+	MaxDeliveries    int
+}
+
+//AppWebhook Webhook subscription of an application and the status of its recent deliveries
+type AppWebhook struct {
+	App                 string     `json:"application"`
+	Organization        string     `json:"organization"`
+	ID                  string     `json:"id"`
+	URL                 string     `json:"url"`
+	Host                string     `json:"host"`
+	Include             []string   `json:"include"`
+	Level               string     `json:"level"`
+	Deliveries          int        `json:"deliveries"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastDelivery        *time.Time `json:"last_delivery,omitempty"`
+	LastStatus          string     `json:"last_status"`
+	Flags               []string   `json:"flags"`
+}
+
+//WebhookDelivery Delivery of a webhook, most recent first when checked
+type WebhookDelivery struct {
+	CreatedAt time.Time
+	Status    string
+}
+
+//ListWebhooks Collect the webhooks of every application with their recent delivery status
+func (hls *HerokuListing) ListWebhooks(herokuOrgs []HerokuOrganization, opts WebhookOptions) ([]AppWebhook, error) {
+	var appsWebhooks []AppWebhook
+	var wg = &sync.WaitGroup{}
+	var mutex = &sync.Mutex{}
+	errChannel := make(chan error, countApps(herokuOrgs))
+
+	rl := ratelimit.New(40) // per second
+
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			wg.Add(1)
+			go func(app heroku.OrganizationApp) {
+				defer wg.Done()
+				rl.Take()
+				webhooks, err := hls.Cli.AppWebhookList(hls.ctx, app.ID, &heroku.ListRange{Field: "id"})
+				if err != nil {
+					errChannel <- err
+					return
+				}
+				if len(webhooks) == 0 {
+					return
+				}
+				var webhookIDs []string
+				for _, webhook := range webhooks {
+					webhookIDs = append(webhookIDs, webhook.ID)
+				}
+				deliveriesByWebhook, err := hls.getWebhookDeliveries(app.ID, webhookIDs, opts)
+				if err != nil {
+					errChannel <- err
+					return
+				}
+
+				var appWebhooks []AppWebhook
+				for _, webhook := range webhooks {
+					appWebhook := AppWebhook{
+						App:          app.Name,
+						Organization: appOrganization(app),
+						ID:           webhook.ID,
+						Include:      webhook.Include,
+						Level:        webhook.Level,
+					}
+					appWebhook.URL, appWebhook.Host = RedactURL(webhook.URL)
+					CheckWebhook(&appWebhook, deliveriesByWebhook[webhook.ID], opts)
+					appWebhooks = append(appWebhooks, appWebhook)
+				}
+				mutex.Lock()
+				appsWebhooks = append(appsWebhooks, appWebhooks...)
+				mutex.Unlock()
+			}(app.App)
+		}
+	}
+	wg.Wait()
+	close(errChannel)
+
+	sort.Slice(appsWebhooks, func(i, j int) bool {
+		if appsWebhooks[i].App != appsWebhooks[j].App {
+			return appsWebhooks[i].App < appsWebhooks[j].App
+		}
+		return appsWebhooks[i].URL < appsWebhooks[j].URL
+	})
+	return appsWebhooks, <-errChannel
+}
+
+//getWebhookDeliveries Most recent deliveries of the webhooks of an application, by webhook ID
+//Pages are fetched until every webhook has opts.FailedDeliveries deliveries or a delivery which did not fail
+func (hls *HerokuListing) getWebhookDeliveries(appID string, webhookIDs []string, opts WebhookOptions) (map[string][]WebhookDelivery, error) {
+	needed := opts.FailedDeliveries
+	if needed < 1 {
+		needed = 1
+	}
+	deliveriesByWebhook := make(map[string][]WebhookDelivery)
+	// a webhook is decided once it has enough deliveries or its failure streak is broken
+	decided := func() bool {
+		for _, webhookID := range webhookIDs {
+			deliveries := deliveriesByWebhook[webhookID]
+			if len(deliveries) >= needed || hasDeliveryNotFailed(deliveries) {
+				continue
+			}
+			return false
+		}
+		return true
+	}
+
+	listRange := &heroku.ListRange{Field: "created_at", Max: opts.MaxDeliveries, Descending: true}
+	var nextRange string
+	for page := 0; page < maxDeliveryPages && !decided(); page++ {
+		deliveries, next, err := hls.listWebhookDeliveries(appID, listRange, nextRange)
+		if err != nil {
+			return deliveriesByWebhook, err
+		}
+		for _, delivery := range deliveries {
+			deliveriesByWebhook[delivery.Webhook.ID] = append(deliveriesByWebhook[delivery.Webhook.ID], WebhookDelivery{
+				CreatedAt: delivery.CreatedAt,
+				Status:    delivery.Status,
+			})
+		}
+		// the API only sends Next-Range when more deliveries are left
+		if next == "" {
+			break
+		}
+		nextRange = next
+	}
+	return deliveriesByWebhook, nil
+}
+
+//listWebhookDeliveries One page of deliveries of an application and the Next-Range of the API, empty on the last page
+//The first page is requested with listRange, the following ones with the Next-Range of the previous page
+//so deliveries sharing the created_at of a page boundary are neither skipped nor listed twice
+func (hls *HerokuListing) listWebhookDeliveries(appID string, listRange *heroku.ListRange, nextRange string) (heroku.AppWebhookDeliveryListResult, string, error) {
+	var deliveries heroku.AppWebhookDeliveryListResult
+	req, err := hls.Cli.NewRequest(hls.ctx, "GET", fmt.Sprintf("/apps/%v/webhook-deliveries", appID), nil, nil)
+	if err != nil {
+		return deliveries, "", err
+	}
+	if nextRange != "" {
+		req.Header.Set("Range", nextRange)
+	} else {
+		listRange.SetHeader(req)
+	}
+	resp, err := hls.httpClient.Do(req)
+	if err != nil {
+		return deliveries, "", err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&deliveries); err != nil {
+		return deliveries, "", err
+	}
+	return deliveries, resp.Header.Get("Next-Range"), nil
+}
+
+func hasDeliveryNotFailed(deliveries []WebhookDelivery) bool {
+	for _, delivery := range deliveries {
+		if delivery.Status != DeliveryStatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+//CheckWebhook Summarize the deliveries of a webhook and flag it
+//A webhook is failing when its last opts.FailedDeliveries deliveries failed
+func CheckWebhook(webhook *AppWebhook, deliveries []WebhookDelivery, opts WebhookOptions) {
+	sorted := append([]WebhookDelivery{}, deliveries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	webhook.Deliveries = len(sorted)
+	if len(sorted) > 0 {
+		webhook.LastDelivery = &sorted[0].CreatedAt
+		webhook.LastStatus = sorted[0].Status
+	}
+	for _, delivery := range sorted {
+		if delivery.Status != DeliveryStatusFailed {
+			break
+		}
+		webhook.ConsecutiveFailures++
+	}
+
+	if opts.FailedDeliveries > 0 && webhook.ConsecutiveFailures >= opts.FailedDeliveries {
+		webhook.Flags = append(webhook.Flags, WebhookFlagFailing)
+	}
+	if len(opts.AllowedHosts) > 0 && !hostAllowed(webhook.Host, opts.AllowedHosts) {
+		webhook.Flags = append(webhook.Flags, WebhookFlagUnknownHost)
+	}
+}
+
+//FlaggedWebhooks Webhooks with at least one flag
+func FlaggedWebhooks(appsWebhooks []AppWebhook) []AppWebhook {
+	var flagged []AppWebhook
+	for _, webhook := range appsWebhooks {
+		if len(webhook.Flags) > 0 {
+			flagged = append(flagged, webhook)
+		}
+	}
+	return flagged
+}
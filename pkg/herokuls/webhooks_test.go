@@ -0,0 +1,54 @@
+package herokuls
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	heroku "github.com/heroku/heroku-go/v3"
+)
+
+func TestGetWebhookDeliveriesFollowsNextRange(t *testing.T) {
+	// both pages hold a delivery created at the same second, the boundary must not skip any
+	pages := map[string]string{
+		"created_at ..; max=2,order=desc": `[
+			{"id":"d1","created_at":"2020-06-30T12:00:00Z","status":"failed","webhook":{"id":"w1"}},
+			{"id":"d2","created_at":"2020-06-30T11:00:00Z","status":"failed","webhook":{"id":"w1"}}]`,
+		"id ]d2..; max=2,order=desc": `[
+			{"id":"d3","created_at":"2020-06-30T11:00:00Z","status":"failed","webhook":{"id":"w1"}},
+			{"id":"d4","created_at":"2020-06-30T10:00:00Z","status":"succeeded","webhook":{"id":"w1"}}]`,
+	}
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader := r.Header.Get("Range")
+		requested = append(requested, rangeHeader)
+		page, ok := pages[rangeHeader]
+		if !ok {
+			http.Error(w, "unexpected range "+rangeHeader, http.StatusBadRequest)
+			return
+		}
+		if rangeHeader == "created_at ..; max=2,order=desc" {
+			w.Header().Set("Next-Range", "id ]d2..; max=2,order=desc")
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	service := heroku.NewService(server.Client())
+	service.URL = server.URL
+	hls := &HerokuListing{Cli: service, ctx: context.Background(), httpClient: server.Client()}
+
+	deliveriesByWebhook, err := hls.getWebhookDeliveries("app", []string{"w1"}, WebhookOptions{FailedDeliveries: 4, MaxDeliveries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 2 {
+		t.Errorf("requested ranges %q, want 2 pages", requested)
+	}
+	if got := len(deliveriesByWebhook["w1"]); got != 4 {
+		t.Errorf("got %d deliveries, want 4", got)
+	}
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// testReport two failing rules, one with a warning only and one passing
+func testReport() findings.Report {
+	report := findings.NewReport("policy")
+	report.AddRule("web-redundancy", "production web runs on two dynos at least")
+	report.AddRule("typo", "")
+	report.AddRule("modern-stack", "no app on heroku-16")
+	report.AddRule("runtime-metrics", "")
+	report.Add("web-redundancy", findings.LevelError, "acme/shop-production", "1 web dyno(s), 2 required")
+	report.Add("web-redundancy", findings.LevelError, "acme/shop-api", "0 web dyno(s), 2 required")
+	report.Add("typo", findings.LevelWarning, "typo", "no matching apps, check the match selector")
+	report.Add("runtime-metrics", findings.LevelError, "other/blog", "feature log-runtime-metrics disabled")
+	return report
+}
+
+// renderToString run render against a temporary file, the writers only take files
+func renderToString(t *testing.T, render func(f *os.File)) string {
+	f, err := ioutil.TempFile("", "findings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	render(f)
+	f.Close()
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs, got:\n%s", path, got)
+	}
+}
+
+func TestJUnitRenderFindings(t *testing.T) {
+	got := renderToString(t, func(f *os.File) {
+		NewJUnitWriter(f).RenderFindings(testReport())
+	})
+	assertGolden(t, "findings.junit.xml", got)
+}
+
+func TestSarifRenderFindings(t *testing.T) {
+	got := renderToString(t, func(f *os.File) {
+		NewSarifWriter(f, "1.2.3").RenderFindings(testReport())
+	})
+	assertGolden(t, "findings.sarif.json", got)
+}
@@ -0,0 +1,472 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shinji62/heroku-asset-listing/pkg/herokuls"
+)
+
+// listSeparator separate multiple values inside a single csv field
+const listSeparator = ";"
+
+type CsvWriter struct {
+	fileOutput *os.File
+}
+
+func NewCsvWriter(output *os.File) *CsvWriter {
+	return &CsvWriter{
+		fileOutput: output,
+	}
+}
+
+func (c *CsvWriter) RenderApps(herokuOrgs []herokuls.HerokuOrganization, dynoSize map[string]int, dynoUnitPrice int) {
+	records := [][]string{{"Name", "Released", "Updated", "Dynos", "d.units", "Price", "Addons", "Stack", "Attached Addons"}}
+	for _, org := range herokuOrgs {
+		for _, app := range org.Apps {
+			records = append(records, appRecord(app, dynoSize, dynoUnitPrice))
+		}
+	}
+	c.render(records)
+}
+
+// RenderAppsByEnvironment apps prefixed by their environment, each environment ends with a subtotal record
+func (c *CsvWriter) RenderAppsByEnvironment(groups []herokuls.EnvironmentGroup, dynoSize map[string]int, dynoUnitPrice int) {
+	records := [][]string{{"Environment", "Name", "Released", "Updated", "Dynos", "d.units", "Price", "Addons", "Stack", "Attached Addons"}}
+	for _, group := range groups {
+		for _, app := range group.Apps {
+			records = append(records, append([]string{group.Environment}, appRecord(app, dynoSize, dynoUnitPrice)...))
+		}
+		records = append(records, []string{group.Environment, "subtotal", "", "", "", strconv.Itoa(group.DynoUnits), strconv.Itoa(group.DynoUnits * dynoUnitPrice), formatCost(group.AddOnCost), "", ""})
+	}
+	c.render(records)
+}
+
+func appRecord(app herokuls.HerokuApp, dynoSize map[string]int, dynoUnitPrice int) []string {
+	dynosByApp := herokuls.CountDynoTypeByApp(app.Dynos)
+	totalDynosUnit := herokuls.CountTotalDynoUnitByApp(dynosByApp, dynoSize)
+	var dynos []string
+	for _, dyno := range dynosByApp {
+		dynos = append(dynos, dyno.DynoSize+" "+strconv.Itoa(dyno.Total))
+	}
+	var addOns []string
+	for _, addOn := range herokuls.CountAddOnsTypeByApp(app.AddOns) {
+		addOns = append(addOns, addOn.Name+" "+strconv.Itoa(addOn.Total))
+	}
+	var attachedAddOns []string
+	for _, attached := range app.AttachedAddOns {
+		attachedAddOns = append(attachedAddOns, attached.Service+" ("+attached.BillingApp+")")
+	}
+	return []string{
+		app.App.Name,
+		formatDate(app.App.ReleasedAt),
+		formatDate(&app.App.UpdatedAt),
+		strings.Join(dynos, listSeparator),
+		strconv.Itoa(totalDynosUnit),
+		strconv.Itoa(totalDynosUnit * dynoUnitPrice),
+		strings.Join(addOns, listSeparator),
+		app.App.Stack.Name,
+		strings.Join(attachedAddOns, listSeparator),
+	}
+}
+
+func (c *CsvWriter) RenderAccess(appsAccess []herokuls.AppAccess) {
+	records := [][]string{{"Name", "Organization", "User", "Role", "Federated", "Permissions"}}
+	for _, appAccess := range appsAccess {
+		for _, collaborator := range appAccess.Collaborators {
+			records = append(records, []string{
+				appAccess.App,
+				appAccess.Organization,
+				collaborator.Email,
+				collaborator.Role,
+				strconv.FormatBool(collaborator.Federated),
+				strings.Join(collaborator.Permissions, listSeparator),
+			})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderUserAccess(usersAccess []herokuls.UserAccess) {
+	records := [][]string{{"User", "Name", "Organization", "Role", "Permissions"}}
+	for _, userAccess := range usersAccess {
+		for _, app := range userAccess.Apps {
+			records = append(records, []string{
+				userAccess.Email,
+				app.App,
+				app.Organization,
+				app.Role,
+				strings.Join(app.Permissions, listSeparator),
+			})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderMembers(report herokuls.MembersReport) {
+	records := [][]string{{"Type", "User", "Name", "Team", "Role", "Federated", "2FA", "Flags", "Invited By"}}
+	for _, member := range report.Members {
+		for _, team := range member.Teams {
+			records = append(records, []string{
+				"member",
+				member.Email,
+				member.Name,
+				team.Team,
+				team.Role,
+				strconv.FormatBool(member.Federated),
+				strconv.FormatBool(member.TwoFactorAuthentication),
+				strings.Join(member.Flags, listSeparator),
+				"",
+			})
+		}
+	}
+	for _, invitation := range report.Invitations {
+		records = append(records, []string{"invitation", invitation.Email, "", invitation.Team, invitation.Role, "", "", "", invitation.InvitedBy})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderIdentityAccess(identity herokuls.IdentityAccess) {
+	records := [][]string{{"User", "Kind", "Team", "Name", "Role", "Detail"}}
+	for _, path := range identity.Paths {
+		records = append(records, []string{identity.Email, path.Kind, path.Team, path.App, path.Role, path.Detail})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderCertificates(appsCerts []herokuls.AppCertificates) {
+	records := [][]string{{"Name", "Organization", "ACM", "Endpoint", "Type", "Subject", "SANs", "Issuer", "Expires", "Expires In Days", "Flags", "Uncovered Domains", "Error"}}
+	for _, appCerts := range appsCerts {
+		appRecord := []string{appCerts.App, appCerts.Organization, strconv.FormatBool(appCerts.Acm)}
+		uncovered := strings.Join(appCerts.UncoveredDomains, listSeparator)
+		if len(appCerts.Certificates) == 0 {
+			records = append(records, append(appRecord, "", "", "", "", "", "", "", strings.Join(appCerts.Flags, listSeparator), uncovered, appCerts.Error))
+		}
+		for _, certificate := range appCerts.Certificates {
+			flags := append(append([]string{}, appCerts.Flags...), certificate.Flags...)
+			records = append(records, append(append([]string{}, appRecord...),
+				certificate.Endpoint,
+				certificate.Type,
+				certificate.Subject,
+				strings.Join(certificate.SANs, listSeparator),
+				certificate.Issuer,
+				formatDate(&certificate.NotAfter),
+				strconv.Itoa(certificate.ExpiresInDays),
+				strings.Join(flags, listSeparator),
+				uncovered,
+				certificate.Error,
+			))
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderDomains(appDomains []herokuls.AppDomain) {
+	records := [][]string{{"Name", "Organization", "Hostname", "Kind", "Target", "Status", "ACM", "DNS", "Resolved Target"}}
+	for _, domain := range appDomains {
+		records = append(records, []string{
+			domain.App,
+			domain.Organization,
+			domain.Hostname,
+			domain.Kind,
+			domain.CName,
+			domain.Status,
+			domain.AcmStatus,
+			domain.DNSStatus,
+			domain.ResolvedTarget,
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderConfig(appsConfig []herokuls.AppConfig) {
+	records := [][]string{{"Name", "Organization", "Key", "Fingerprint", "Classes"}}
+	for _, appConfig := range appsConfig {
+		for _, configVar := range appConfig.Vars {
+			records = append(records, []string{appConfig.App, appConfig.Organization, configVar.Key, configVar.Fingerprint, strings.Join(configVar.Classes, listSeparator)})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderSharedConfig(sharedValues []herokuls.SharedConfigValue) {
+	records := [][]string{{"Fingerprint", "Keys", "Apps"}}
+	for _, shared := range sharedValues {
+		records = append(records, []string{shared.Fingerprint, strings.Join(shared.Keys, listSeparator), strings.Join(shared.Apps, listSeparator)})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderStacks(report herokuls.StackReport) {
+	records := [][]string{{"Name", "Organization", "Stack", "Status", "EOL"}}
+	for _, app := range report.Apps {
+		records = append(records, []string{app.App, app.Organization, app.Stack, app.Status, formatDate(app.EOL)})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderReleases(activities []herokuls.ReleaseActivity) {
+	records := [][]string{{"Name", "Organization", "Version", "Last Release", "Last Deploy", "Deployer", "Releases", "Deploys", "Rollbacks", "Deploys/Day", "Flags"}}
+	for _, activity := range activities {
+		records = append(records, []string{
+			activity.App,
+			activity.Organization,
+			strconv.Itoa(activity.Version),
+			formatDate(activity.LastRelease),
+			formatDate(activity.LastDeploy),
+			activity.LastDeployer,
+			strconv.Itoa(activity.Releases),
+			strconv.Itoa(activity.Deploys),
+			strconv.Itoa(activity.Rollbacks),
+			strconv.FormatFloat(activity.DeploysPerDay, 'f', 2, 64),
+			strings.Join(activity.Flags, listSeparator),
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderIdleApps(idleApps []herokuls.IdleApp) {
+	records := [][]string{{"Name", "Organization", "Score", "Signals", "Last Release", "Addons $/month"}}
+	for _, idleApp := range idleApps {
+		records = append(records, []string{
+			idleApp.App,
+			idleApp.Organization,
+			strconv.Itoa(idleApp.Score),
+			strings.Join(idleApp.Signals, listSeparator),
+			formatDate(idleApp.LastRelease),
+			formatCost(idleApp.AddOnMonthlyCost),
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderRecommendations(recommendations []herokuls.Recommendation) {
+	records := [][]string{{"Name", "Organization", "Process", "Rule", "Current", "Suggested", "Savings $/month"}}
+	for _, recommendation := range recommendations {
+		records = append(records, []string{
+			recommendation.App,
+			recommendation.Organization,
+			recommendation.ProcessType,
+			recommendation.Rule,
+			recommendation.Current,
+			recommendation.Suggested,
+			formatSavings(recommendation),
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderChargeback(costs []herokuls.CostCenterCost) {
+	records := [][]string{{"Cost Center", "Apps", "Dynos $/month", "Addons $/month", "Total $/month"}}
+	for _, cost := range costs {
+		records = append(records, []string{cost.CostCenter, strings.Join(cost.Apps, listSeparator), formatCost(cost.DynoCost), formatCost(cost.AddOnCost), formatCost(cost.Total)})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderInvoices(teamsInvoices []herokuls.TeamInvoices) {
+	records := [][]string{{"Team", "Computed $/month", "Computed Dyno Units", "Invoice", "Period Start", "Period End", "Total $", "Platform $", "Addons $", "Database $", "Dyno Units", "Delta $", "Change %"}}
+	for _, teamInvoices := range teamsInvoices {
+		for _, invoice := range teamInvoices.Invoices {
+			records = append(records, []string{
+				teamInvoices.Team,
+				formatCost(teamInvoices.ComputedCost),
+				strconv.Itoa(teamInvoices.ComputedDynoUnits),
+				strconv.Itoa(invoice.Number),
+				invoice.PeriodStart,
+				invoice.PeriodEnd,
+				formatCost(invoice.Total),
+				formatCost(invoice.Platform),
+				formatCost(invoice.AddOns),
+				formatCost(invoice.Database),
+				strconv.FormatFloat(invoice.DynoUnits, 'f', 1, 64),
+				formatCost(invoice.Delta),
+				strconv.FormatFloat(invoice.Change, 'f', 1, 64),
+			})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderPipelines(topologies []herokuls.PipelineTopology) {
+	records := [][]string{{"Pipeline", "Stage", "Apps", "Dynos $/month", "Last Promotion", "Error"}}
+	for _, topology := range topologies {
+		for _, stage := range topology.Stages {
+			records = append(records, []string{topology.Pipeline, stage.Stage, strings.Join(stage.Apps, listSeparator), formatCost(stage.DynoCost), formatDate(topology.LastPromotion), stage.Error})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderAddOns(inventory herokuls.AddOnInventory) {
+	records := [][]string{{"Name", "Service", "Plan", "State", "Organization", "Billing App", "Attached Apps", "Region", "Created", "$/month", "Flags"}}
+	for _, addOn := range inventory.AddOns {
+		records = append(records, []string{
+			addOn.Name,
+			addOn.Service,
+			addOn.Plan,
+			addOn.State,
+			addOn.Organization,
+			addOn.BillingApp,
+			strings.Join(addOn.AttachedApps, listSeparator),
+			addOn.Region,
+			formatDate(&addOn.CreatedAt),
+			formatCost(addOn.MonthlyCost),
+			strings.Join(addOn.Flags, listSeparator),
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderAllowlists(teamsAllowlist []herokuls.TeamAllowlist) {
+	records := [][]string{{"Team", "Name", "Addon", "Service", "Reasons", "Team Flags"}}
+	for _, teamAllowlist := range teamsAllowlist {
+		if len(teamAllowlist.NonCompliantAddOns) == 0 && len(teamAllowlist.Flags) > 0 {
+			records = append(records, []string{teamAllowlist.Team, "", "", "", "", strings.Join(teamAllowlist.Flags, listSeparator)})
+		}
+		for _, addOn := range teamAllowlist.NonCompliantAddOns {
+			records = append(records, []string{
+				teamAllowlist.Team,
+				addOn.App,
+				addOn.AddOn,
+				addOn.Service,
+				strings.Join(addOn.Reasons, listSeparator),
+				strings.Join(teamAllowlist.Flags, listSeparator),
+			})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderPolicy(results []herokuls.PolicyResult) {
+	records := [][]string{{"Rule", "Passed", "Evaluated", "Name", "Organization", "Reasons"}}
+	for _, result := range results {
+		if result.Passed {
+			var reason string
+			if result.Evaluated == 0 {
+				reason = herokuls.PolicyNoMatchingApps
+			}
+			records = append(records, []string{result.Rule, "true", strconv.Itoa(result.Evaluated), "", "", reason})
+		}
+		for _, violation := range result.Violations {
+			records = append(records, []string{
+				result.Rule,
+				"false",
+				strconv.Itoa(result.Evaluated),
+				violation.App,
+				violation.Organization,
+				strings.Join(violation.Reasons, listSeparator),
+			})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderDrains(appsDrains []herokuls.AppDrains) {
+	records := [][]string{{"Name", "Organization", "Environment", "Drain", "Host", "Source", "Flags"}}
+	for _, appDrains := range appsDrains {
+		if len(appDrains.Drains) == 0 {
+			records = append(records, []string{appDrains.App, appDrains.Organization, appDrains.Environment, "", "", "", strings.Join(appDrains.Flags, listSeparator)})
+		}
+		for _, drain := range appDrains.Drains {
+			records = append(records, []string{
+				appDrains.App,
+				appDrains.Organization,
+				appDrains.Environment,
+				drain.URL,
+				drain.Host,
+				drain.Source,
+				strings.Join(drain.Flags, listSeparator),
+			})
+		}
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderWebhooks(appsWebhooks []herokuls.AppWebhook) {
+	records := [][]string{{"Name", "Organization", "URL", "Host", "Include", "Level", "Last Delivery", "Last Status", "Deliveries", "Consecutive Failures", "Flags"}}
+	for _, webhook := range appsWebhooks {
+		records = append(records, []string{
+			webhook.App,
+			webhook.Organization,
+			webhook.URL,
+			webhook.Host,
+			strings.Join(webhook.Include, listSeparator),
+			webhook.Level,
+			formatDate(webhook.LastDelivery),
+			webhook.LastStatus,
+			strconv.Itoa(webhook.Deliveries),
+			strconv.Itoa(webhook.ConsecutiveFailures),
+			strings.Join(webhook.Flags, listSeparator),
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderFeatures(report herokuls.FeatureReport) {
+	records := [][]string{append([]string{"Name", "Organization", "Environment"}, report.Features...)}
+	for _, app := range report.Apps {
+		records = append(records, append([]string{app.App, app.Organization, app.Environment}, featureMatrixRow(report.Features, app.Enabled, "true")...))
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) RenderBuilds(appsBuilds []herokuls.AppBuild) {
+	records := [][]string{{"Name", "Organization", "Stack", "Buildpacks", "Last Build", "Last Build Stack", "Status", "Duration Seconds", "Slug Bytes", "Flags"}}
+	for _, appBuild := range appsBuilds {
+		records = append(records, []string{
+			appBuild.App,
+			appBuild.Organization,
+			appBuild.Stack,
+			formatBuildpacks(appBuild.Buildpacks, listSeparator),
+			formatDate(appBuild.LastBuildAt),
+			appBuild.LastBuildStack,
+			appBuild.LastBuildStatus,
+			strconv.Itoa(appBuild.BuildSeconds),
+			strconv.Itoa(appBuild.SlugSize),
+			strings.Join(appBuild.Flags, listSeparator),
+		})
+	}
+	c.render(records)
+}
+
+func (c *CsvWriter) render(records [][]string) {
+	w := csv.NewWriter(c.fileOutput)
+	if err := w.WriteAll(records); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 2, 64)
+}
+
+func formatSavings(recommendation herokuls.Recommendation) string {
+	if recommendation.SavingsUnknown {
+		return "unknown"
+	}
+	return formatCost(recommendation.MonthlySavings)
+}
+
+func formatBuildpacks(buildpacks []herokuls.Buildpack, separator string) string {
+	names := make([]string, 0, len(buildpacks))
+	for _, buildpack := range buildpacks {
+		names = append(names, buildpack.String())
+	}
+	return strings.Join(names, separator)
+}
+
+func formatMegabytes(bytes int) string {
+	return strconv.FormatFloat(float64(bytes)/1024/1024, 'f', 1, 64)
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
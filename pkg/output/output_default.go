@@ -4,4 +4,41 @@ import "github.com/shinji62/heroku-asset-listing/pkg/herokuls"
 
 type Output interface {
 	RenderApps(herokuOrgs []herokuls.HerokuOrganization, dynoSize map[string]int, dynoUnitPrice int)
+	RenderAppsByEnvironment(groups []herokuls.EnvironmentGroup, dynoSize map[string]int, dynoUnitPrice int)
+	RenderAccess(appsAccess []herokuls.AppAccess)
+	RenderUserAccess(usersAccess []herokuls.UserAccess)
+	RenderMembers(report herokuls.MembersReport)
+	RenderIdentityAccess(identity herokuls.IdentityAccess)
+	RenderCertificates(appsCerts []herokuls.AppCertificates)
+	RenderDomains(appDomains []herokuls.AppDomain)
+	RenderConfig(appsConfig []herokuls.AppConfig)
+	RenderSharedConfig(sharedValues []herokuls.SharedConfigValue)
+	RenderStacks(report herokuls.StackReport)
+	RenderReleases(activities []herokuls.ReleaseActivity)
+	RenderIdleApps(idleApps []herokuls.IdleApp)
+	RenderRecommendations(recommendations []herokuls.Recommendation)
+	RenderChargeback(costs []herokuls.CostCenterCost)
+	RenderInvoices(teamsInvoices []herokuls.TeamInvoices)
+	RenderPipelines(topologies []herokuls.PipelineTopology)
+	RenderAddOns(inventory herokuls.AddOnInventory)
+	RenderAllowlists(teamsAllowlist []herokuls.TeamAllowlist)
+	RenderPolicy(results []herokuls.PolicyResult)
+	RenderDrains(appsDrains []herokuls.AppDrains)
+	RenderWebhooks(appsWebhooks []herokuls.AppWebhook)
+	RenderFeatures(report herokuls.FeatureReport)
+	RenderBuilds(appsBuilds []herokuls.AppBuild)
+}
+
+// featureMatrixRow one cell by feature, set to enabledMark when the feature is enabled
+func featureMatrixRow(features []string, enabled []string, enabledMark string) []string {
+	row := make([]string, len(features))
+	for i, feature := range features {
+		for _, name := range enabled {
+			if name == feature {
+				row[i] = enabledMark
+				break
+			}
+		}
+	}
+	return row
 }
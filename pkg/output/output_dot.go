@@ -0,0 +1,48 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shinji62/heroku-asset-listing/pkg/herokuls"
+)
+
+// DotWriter render pipelines as a Graphviz DOT graph
+type DotWriter struct {
+	fileOutput *os.File
+}
+
+func NewDotWriter(output *os.File) *DotWriter {
+	return &DotWriter{
+		fileOutput: output,
+	}
+}
+
+// RenderPipelines one cluster by pipeline, stages are linked in promotion order
+func (d *DotWriter) RenderPipelines(topologies []herokuls.PipelineTopology) {
+	fmt.Fprintln(d.fileOutput, "digraph pipelines {")
+	fmt.Fprintln(d.fileOutput, "  rankdir=LR;")
+	fmt.Fprintln(d.fileOutput, "  node [shape=box];")
+	for i, topology := range topologies {
+		fmt.Fprintf(d.fileOutput, "  subgraph cluster_%d {\n", i)
+		label := topology.Pipeline
+		if topology.LastPromotion != nil {
+			label += "\nlast promotion " + formatDate(topology.LastPromotion)
+		}
+		fmt.Fprintf(d.fileOutput, "    label=%q;\n", label)
+		var previous string
+		for _, stage := range topology.Stages {
+			stageID := fmt.Sprintf("p%d_%s", i, stage.Stage)
+			fmt.Fprintf(d.fileOutput, "    %q [shape=folder, label=%q];\n", stageID, stage.Stage+"\n"+formatCost(stage.DynoCost)+"$/month")
+			for _, app := range stage.Apps {
+				fmt.Fprintf(d.fileOutput, "    %q -> %q;\n", stageID, app)
+			}
+			if previous != "" {
+				fmt.Fprintf(d.fileOutput, "    %q -> %q [style=dashed];\n", previous, stageID)
+			}
+			previous = stageID
+		}
+		fmt.Fprintln(d.fileOutput, "  }")
+	}
+	fmt.Fprintln(d.fileOutput, "}")
+}
@@ -21,14 +21,110 @@ func NewJsonWriter(fileOutput *os.File, prettyJson bool) *JsonWriter {
 }
 
 func (j *JsonWriter) RenderApps(herokuOrgs []herokuls.HerokuOrganization, dynoSize map[string]int, dynoUnitPrice int) {
+	j.render(herokuOrgs)
+}
+
+func (j *JsonWriter) RenderAppsByEnvironment(groups []herokuls.EnvironmentGroup, dynoSize map[string]int, dynoUnitPrice int) {
+	j.render(groups)
+}
+
+func (j *JsonWriter) RenderAccess(appsAccess []herokuls.AppAccess) {
+	j.render(appsAccess)
+}
+
+func (j *JsonWriter) RenderUserAccess(usersAccess []herokuls.UserAccess) {
+	j.render(usersAccess)
+}
+
+func (j *JsonWriter) RenderMembers(report herokuls.MembersReport) {
+	j.render(report)
+}
+
+func (j *JsonWriter) RenderIdentityAccess(identity herokuls.IdentityAccess) {
+	j.render(identity)
+}
+
+func (j *JsonWriter) RenderCertificates(appsCerts []herokuls.AppCertificates) {
+	j.render(appsCerts)
+}
+
+func (j *JsonWriter) RenderDomains(appDomains []herokuls.AppDomain) {
+	j.render(appDomains)
+}
+
+func (j *JsonWriter) RenderConfig(appsConfig []herokuls.AppConfig) {
+	j.render(appsConfig)
+}
+
+func (j *JsonWriter) RenderSharedConfig(sharedValues []herokuls.SharedConfigValue) {
+	j.render(sharedValues)
+}
+
+func (j *JsonWriter) RenderStacks(report herokuls.StackReport) {
+	j.render(report)
+}
+
+func (j *JsonWriter) RenderReleases(activities []herokuls.ReleaseActivity) {
+	j.render(activities)
+}
+
+func (j *JsonWriter) RenderIdleApps(idleApps []herokuls.IdleApp) {
+	j.render(idleApps)
+}
+
+func (j *JsonWriter) RenderRecommendations(recommendations []herokuls.Recommendation) {
+	j.render(recommendations)
+}
+
+func (j *JsonWriter) RenderChargeback(costs []herokuls.CostCenterCost) {
+	j.render(costs)
+}
+
+func (j *JsonWriter) RenderInvoices(teamsInvoices []herokuls.TeamInvoices) {
+	j.render(teamsInvoices)
+}
+
+func (j *JsonWriter) RenderPipelines(topologies []herokuls.PipelineTopology) {
+	j.render(topologies)
+}
+
+func (j *JsonWriter) RenderAddOns(inventory herokuls.AddOnInventory) {
+	j.render(inventory)
+}
+
+func (j *JsonWriter) RenderAllowlists(teamsAllowlist []herokuls.TeamAllowlist) {
+	j.render(teamsAllowlist)
+}
+
+func (j *JsonWriter) RenderPolicy(results []herokuls.PolicyResult) {
+	j.render(results)
+}
+
+func (j *JsonWriter) RenderDrains(appsDrains []herokuls.AppDrains) {
+	j.render(appsDrains)
+}
+
+func (j *JsonWriter) RenderWebhooks(appsWebhooks []herokuls.AppWebhook) {
+	j.render(appsWebhooks)
+}
+
+func (j *JsonWriter) RenderFeatures(report herokuls.FeatureReport) {
+	j.render(report)
+}
+
+func (j *JsonWriter) RenderBuilds(appsBuilds []herokuls.AppBuild) {
+	j.render(appsBuilds)
+}
+
+func (j *JsonWriter) render(v interface{}) {
 
 	var json = jsoniter.ConfigCompatibleWithStandardLibrary
 	var b []byte
 	var err error
 	if j.pretty {
-		b, err = json.MarshalIndent(herokuOrgs, "", "  ")
+		b, err = json.MarshalIndent(v, "", "  ")
 	} else {
-		b, err = json.Marshal(herokuOrgs)
+		b, err = json.Marshal(v)
 	}
 
 	if err != nil {
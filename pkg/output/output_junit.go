@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+)
+
+// JUnitWriter render audit findings as a JUnit XML report, one test case by finding
+// and one passing test case by rule without finding
+type JUnitWriter struct {
+	fileOutput *os.File
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func NewJUnitWriter(output *os.File) *JUnitWriter {
+	return &JUnitWriter{
+		fileOutput: output,
+	}
+}
+
+// RenderFindings an error finding is a failed test case, a warning a passing one with the message as output
+// Test cases are named by target and classed by audit and rule
+func (u *JUnitWriter) RenderFindings(report findings.Report) {
+	suite := junitTestSuite{Name: report.Audit}
+	for _, rule := range report.Rules {
+		className := report.Audit + "." + rule.ID
+		ruleFindings := report.ByRule(rule.ID)
+		if len(ruleFindings) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: rule.ID, ClassName: className})
+			continue
+		}
+		description := rule.Description
+		if description == "" {
+			description = rule.ID
+		}
+		for _, finding := range ruleFindings {
+			testCase := junitTestCase{Name: finding.Target, ClassName: className}
+			if finding.Level == findings.LevelError {
+				suite.Failures++
+				testCase.Failure = &junitFailure{
+					Message: finding.Message,
+					Content: description,
+				}
+			} else {
+				testCase.SystemOut = finding.Level + ": " + finding.Message
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+	}
+	suite.Tests = len(suite.Cases)
+	u.render(junitTestSuites{Suites: []junitTestSuite{suite}})
+}
+
+func (u *JUnitWriter) render(v interface{}) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Fprintf(u.fileOutput, "%s%s\n", xml.Header, b)
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/shinji62/heroku-asset-listing/pkg/findings"
+)
+
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "heroku-listing"
+)
+
+// SarifWriter render audit findings as a SARIF log with one run by audit
+// Targets are logical locations as heroku resources have no file, the audit is the run category
+type SarifWriter struct {
+	fileOutput  *os.File
+	toolVersion string
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool              `json:"tool"`
+	AutomationDetails sarifAutomationDetails `json:"automationDetails"`
+	Results           []sarifResult          `json:"results"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func NewSarifWriter(output *os.File, toolVersion string) *SarifWriter {
+	return &SarifWriter{
+		fileOutput:  output,
+		toolVersion: toolVersion,
+	}
+}
+
+// RenderFindings every finding is a result of the rule it failed
+func (s *SarifWriter) RenderFindings(report findings.Report) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:    sarifToolName,
+			Version: s.toolVersion,
+			Rules:   []sarifRule{},
+		}},
+		AutomationDetails: sarifAutomationDetails{ID: report.Audit + "/"},
+		Results:           []sarifResult{},
+	}
+	ruleIndex := make(map[string]int, len(report.Rules))
+	for i, rule := range report.Rules {
+		ruleIndex[rule.ID] = i
+		description := rule.Description
+		if description == "" {
+			description = rule.ID
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               rule.ID,
+			ShortDescription: sarifMessage{Text: description},
+		})
+	}
+	for _, finding := range report.Findings {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    finding.RuleID,
+			RuleIndex: ruleIndex[finding.RuleID],
+			Level:     finding.Level,
+			Message:   sarifMessage{Text: finding.Target + ": " + finding.Message},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				FullyQualifiedName: finding.Target,
+				Kind:               "resource",
+			}}}},
+		})
+	}
+	s.render(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+func (s *SarifWriter) render(v interface{}) {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Fprintf(s.fileOutput, "%s\n", b)
+}
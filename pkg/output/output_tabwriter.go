@@ -2,7 +2,9 @@ package output
 
 import (
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/shinji62/heroku-asset-listing/pkg/herokuls"
@@ -26,30 +28,470 @@ func formatPrice(totalDynosUnit int, dynoUnitPrice int) string {
 }
 
 func (t *TabWriter) RenderApps(herokuOrgs []herokuls.HerokuOrganization, dynoSize map[string]int, dynoUnitPrice int) {
-	table := tablewriter.NewWriter(t.fileOutput)
-	table.SetHeader([]string{"Name", "Released", "Updated", "Dynos", "d.units", "Addons", "Stack"})
-	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table := t.newTable([]string{"Name", "Released", "Updated", "Dynos", "d.units", "Addons", "Stack"})
 	table.SetCaption(true, "Price by dyno is "+strconv.Itoa(dynoUnitPrice)+" a month. Total price is for a full time running dyno.")
-	table.SetCenterSeparator("|")
 	for _, org := range herokuOrgs {
 		for _, app := range org.Apps {
-			status := "NOT RUNNING"
-			if app.App.Name != "" {
-				dynosByApp := herokuls.CountDynoTypeByApp(app.Dynos)
-				appAddOns := herokuls.CountAddOnsTypeByApp(app.AddOns)
-				price := formatPrice(herokuls.CountTotalDynoUnitByApp(dynosByApp, dynoSize), dynoUnitPrice)
-				if len(dynosByApp) > 0 {
-					status = ""
-				}
-				table.Append([]string{app.App.Name, app.App.ReleasedAt.Format("2006-01-02"), app.App.UpdatedAt.Format("2006-01-02"), status, price, "", app.App.Stack.Name})
-				mergedAddOnDynos := herokuls.MergeAddon(appAddOns, dynosByApp)
-				for _, merge := range mergedAddOnDynos {
-					table.Append([]string{"", "", "", merge[0], "", merge[1], ""})
-				}
+			appendApp(table, app, dynoSize, dynoUnitPrice)
+		}
+
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderAppsByEnvironment(groups []herokuls.EnvironmentGroup, dynoSize map[string]int, dynoUnitPrice int) {
+	table := t.newTable([]string{"Name", "Released", "Updated", "Dynos", "d.units", "Addons", "Stack"})
+	table.SetCaption(true, "Price by dyno is "+strconv.Itoa(dynoUnitPrice)+" a month. Total price is for a full time running dyno.")
+	for _, group := range groups {
+		table.Append([]string{strings.ToUpper(group.Environment), "", "", "", "", "", ""})
+		for _, app := range group.Apps {
+			appendApp(table, app, dynoSize, dynoUnitPrice)
+		}
+		table.Append([]string{"subtotal " + group.Environment, "", "", "", formatPrice(group.DynoUnits, dynoUnitPrice), formatCost(group.AddOnCost) + "$", ""})
+	}
+	table.Render()
+}
+
+// appendApp app row followed by its dynos and add-ons rows
+func appendApp(table *tablewriter.Table, app herokuls.HerokuApp, dynoSize map[string]int, dynoUnitPrice int) {
+	status := "NOT RUNNING"
+	if app.App.Name != "" {
+		dynosByApp := herokuls.CountDynoTypeByApp(app.Dynos)
+		appAddOns := herokuls.CountAddOnsTypeByApp(app.AddOns)
+		price := formatPrice(herokuls.CountTotalDynoUnitByApp(dynosByApp, dynoSize), dynoUnitPrice)
+		if len(dynosByApp) > 0 {
+			status = ""
+		}
+		table.Append([]string{app.App.Name, app.App.ReleasedAt.Format("2006-01-02"), app.App.UpdatedAt.Format("2006-01-02"), status, price, "", app.App.Stack.Name})
+		mergedAddOnDynos := herokuls.MergeAddon(appAddOns, dynosByApp)
+		for _, merge := range mergedAddOnDynos {
+			table.Append([]string{"", "", "", merge[0], "", merge[1], ""})
+		}
+		for _, attached := range app.AttachedAddOns {
+			table.Append([]string{"", "", "", "", "", "-> " + attached.Service + " (" + attached.BillingApp + ")", ""})
+		}
+
+	}
+}
+
+func (t *TabWriter) RenderAccess(appsAccess []herokuls.AppAccess) {
+	table := t.newTable([]string{"Name", "Organization", "User", "Role", "Permissions"})
+	for _, appAccess := range appsAccess {
+		table.Append([]string{appAccess.App, appAccess.Organization, "", "", ""})
+		for _, collaborator := range appAccess.Collaborators {
+			table.Append([]string{"", "", collaborator.Email, collaborator.Role, strings.Join(collaborator.Permissions, ",")})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderUserAccess(usersAccess []herokuls.UserAccess) {
+	table := t.newTable([]string{"User", "Name", "Organization", "Role", "Permissions"})
+	for _, userAccess := range usersAccess {
+		table.Append([]string{userAccess.Email, "", "", "", ""})
+		for _, app := range userAccess.Apps {
+			table.Append([]string{"", app.App, app.Organization, app.Role, strings.Join(app.Permissions, ",")})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderMembers(report herokuls.MembersReport) {
+	table := t.newTable([]string{"User", "Name", "Teams", "Federated", "2FA", "Flags"})
+	for _, member := range report.Members {
+		table.Append([]string{member.Email, member.Name, "", strconv.FormatBool(member.Federated), strconv.FormatBool(member.TwoFactorAuthentication), strings.Join(member.Flags, ",")})
+		for _, team := range member.Teams {
+			table.Append([]string{"", "", team.Team + " (" + team.Role + ")", "", "", ""})
+		}
+	}
+	table.Render()
+
+	if len(report.Invitations) == 0 {
+		return
+	}
+	invitations := t.newTable([]string{"Team", "Invited", "Role", "Invited By", "Since"})
+	invitations.SetCaption(true, "Pending invitations")
+	for _, invitation := range report.Invitations {
+		invitations.Append([]string{invitation.Team, invitation.Email, invitation.Role, invitation.InvitedBy, invitation.CreatedAt.Format("2006-01-02")})
+	}
+	invitations.Render()
+}
+
+func (t *TabWriter) RenderIdentityAccess(identity herokuls.IdentityAccess) {
+	table := t.newTable([]string{"Kind", "Team", "Name", "Role", "Detail"})
+	if len(identity.Notes) > 0 {
+		table.SetCaption(true, strings.Join(identity.Notes, ". "))
+	}
+	for _, path := range identity.Paths {
+		table.Append([]string{path.Kind, path.Team, path.App, path.Role, path.Detail})
+	}
+	table.Render()
+}
 
+func (t *TabWriter) RenderCertificates(appsCerts []herokuls.AppCertificates) {
+	table := t.newTable([]string{"Name", "ACM", "Endpoint", "Subject", "Issuer", "Expires", "Flags"})
+	for _, appCerts := range appsCerts {
+		flags := append([]string{}, appCerts.Flags...)
+		if len(appCerts.UncoveredDomains) > 0 {
+			flags = append(flags, strings.Join(appCerts.UncoveredDomains, ","))
+		}
+		if appCerts.Error != "" {
+			flags = append(flags, "("+appCerts.Error+")")
+		}
+		table.Append([]string{appCerts.App, strconv.FormatBool(appCerts.Acm), "", "", "", "", strings.Join(flags, " ")})
+		for _, certificate := range appCerts.Certificates {
+			expires := ""
+			if !certificate.NotAfter.IsZero() {
+				expires = certificate.NotAfter.Format("2006-01-02") + " (" + strconv.Itoa(certificate.ExpiresInDays) + "d)"
 			}
+			flags := append([]string{}, certificate.Flags...)
+			if certificate.Error != "" {
+				flags = append(flags, "("+certificate.Error+")")
+			}
+			table.Append([]string{"", "", certificate.Endpoint, certificate.Subject, certificate.Issuer, expires, strings.Join(flags, " ")})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderDomains(appDomains []herokuls.AppDomain) {
+	table := t.newTable([]string{"Name", "Hostname", "Kind", "Target", "Status", "ACM", "DNS"})
+	var previousApp string
+	for _, domain := range appDomains {
+		name := domain.App
+		if name == previousApp {
+			name = ""
+		}
+		previousApp = domain.App
+		dns := domain.DNSStatus
+		if domain.DNSStatus == herokuls.DNSStatusMisconfigured {
+			dns += " (" + domain.ResolvedTarget + ")"
+		}
+		table.Append([]string{name, domain.Hostname, domain.Kind, domain.CName, domain.Status, domain.AcmStatus, dns})
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderConfig(appsConfig []herokuls.AppConfig) {
+	table := t.newTable([]string{"Name", "Key", "Fingerprint", "Classes"})
+	for _, appConfig := range appsConfig {
+		table.Append([]string{appConfig.App, "", "", ""})
+		for _, configVar := range appConfig.Vars {
+			table.Append([]string{"", configVar.Key, configVar.Fingerprint, strings.Join(configVar.Classes, ",")})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderSharedConfig(sharedValues []herokuls.SharedConfigValue) {
+	table := t.newTable([]string{"Fingerprint", "Keys", "Apps"})
+	for _, shared := range sharedValues {
+		table.Append([]string{shared.Fingerprint, strings.Join(shared.Keys, ","), strings.Join(shared.Apps, ",")})
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderStacks(report herokuls.StackReport) {
+	table := t.newTable([]string{"Stack", "State", "EOL", "Status", "Apps", "Organization"})
+	for _, usage := range report.Stacks {
+		table.Append([]string{usage.Stack, usage.State, formatDate(usage.EOL), usage.Status, strconv.Itoa(usage.Total), ""})
+		var orgs []string
+		for org := range usage.ByOrganization {
+			orgs = append(orgs, org)
+		}
+		sort.Strings(orgs)
+		for _, org := range orgs {
+			table.Append([]string{"", "", "", "", strconv.Itoa(usage.ByOrganization[org]), org})
+		}
+	}
+	table.Render()
+
+	unsupported := report.AppsOnUnsupportedStack()
+	if len(unsupported) == 0 {
+		return
+	}
+	apps := t.newTable([]string{"Name", "Organization", "Stack", "Status", "EOL"})
+	apps.SetCaption(true, "Apps on deprecated or end of life stacks")
+	for _, app := range unsupported {
+		apps.Append([]string{app.App, app.Organization, app.Stack, app.Status, formatDate(app.EOL)})
+	}
+	apps.Render()
+}
+
+func (t *TabWriter) RenderReleases(activities []herokuls.ReleaseActivity) {
+	table := t.newTable([]string{"Name", "Version", "Last Release", "Last Deploy", "Deployer", "Releases", "Deploys", "Rollbacks", "Deploys/Day", "Flags"})
+	for _, activity := range activities {
+		table.Append([]string{
+			activity.App,
+			strconv.Itoa(activity.Version),
+			formatDate(activity.LastRelease),
+			formatDate(activity.LastDeploy),
+			activity.LastDeployer,
+			strconv.Itoa(activity.Releases),
+			strconv.Itoa(activity.Deploys),
+			strconv.Itoa(activity.Rollbacks),
+			strconv.FormatFloat(activity.DeploysPerDay, 'f', 2, 64),
+			strings.Join(activity.Flags, ","),
+		})
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderIdleApps(idleApps []herokuls.IdleApp) {
+	table := t.newTable([]string{"Name", "Organization", "Score", "Signals", "Last Release", "Addons $/month"})
+	var total float64
+	for _, idleApp := range idleApps {
+		total += idleApp.AddOnMonthlyCost
+		table.Append([]string{
+			idleApp.App,
+			idleApp.Organization,
+			strconv.Itoa(idleApp.Score),
+			strings.Join(idleApp.Signals, ","),
+			formatDate(idleApp.LastRelease),
+			formatCost(idleApp.AddOnMonthlyCost),
+		})
+	}
+	table.SetCaption(true, "Candidates for deletion still cost "+formatCost(total)+"$ a month in add-ons.")
+	table.Render()
+}
+
+func (t *TabWriter) RenderRecommendations(recommendations []herokuls.Recommendation) {
+	table := t.newTable([]string{"Name", "Process", "Rule", "Current", "Suggested", "Savings $/month"})
+	for _, recommendation := range recommendations {
+		table.Append([]string{
+			recommendation.App,
+			recommendation.ProcessType,
+			recommendation.Rule,
+			recommendation.Current,
+			recommendation.Suggested,
+			formatSavings(recommendation),
+		})
+	}
+	table.SetCaption(true, "Estimated savings are "+formatCost(herokuls.TotalSavings(recommendations))+"$ a month.")
+	table.Render()
+}
+
+func (t *TabWriter) RenderChargeback(costs []herokuls.CostCenterCost) {
+	table := t.newTable([]string{"Cost Center", "Apps", "Dynos $/month", "Addons $/month", "Total $/month"})
+	var total float64
+	for _, cost := range costs {
+		total += cost.Total
+		table.Append([]string{cost.CostCenter, strconv.Itoa(len(cost.Apps)), formatCost(cost.DynoCost), formatCost(cost.AddOnCost), formatCost(cost.Total)})
+	}
+	table.SetCaption(true, "Total is "+formatCost(total)+"$ a month for full time running dynos.")
+	table.Render()
+}
+
+func (t *TabWriter) RenderBudgets(statuses []herokuls.BudgetStatus) {
+	table := t.newTable([]string{"Scope", "Name", "Budget $/month", "Actual $/month", "Consumed", "Exceeded"})
+	for _, status := range statuses {
+		exceeded := strconv.FormatBool(status.Exceeded)
+		if status.Unknown {
+			exceeded = "unknown target"
+		}
+		table.Append([]string{
+			status.Scope,
+			status.Name,
+			formatCost(status.Budget),
+			formatCost(status.Actual),
+			strconv.FormatFloat(status.PercentConsumed, 'f', 1, 64) + "%",
+			exceeded,
+		})
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderInvoices(teamsInvoices []herokuls.TeamInvoices) {
+	table := t.newTable([]string{"Team", "Computed $/month", "Period", "Invoice $", "Addons $", "Dyno Units", "Delta $", "Change"})
+	for _, teamInvoices := range teamsInvoices {
+		table.Append([]string{teamInvoices.Team, formatCost(teamInvoices.ComputedCost), "", "", "", strconv.Itoa(teamInvoices.ComputedDynoUnits), "", ""})
+		for _, invoice := range teamInvoices.Invoices {
+			table.Append([]string{
+				"",
+				"",
+				invoice.PeriodStart + " - " + invoice.PeriodEnd,
+				formatCost(invoice.Total),
+				formatCost(invoice.AddOns),
+				strconv.FormatFloat(invoice.DynoUnits, 'f', 1, 64),
+				formatCost(invoice.Delta),
+				strconv.FormatFloat(invoice.Change, 'f', 1, 64) + "%",
+			})
+		}
+	}
+	table.SetCaption(true, "Delta is the invoice total minus the cost computed from the current inventory.")
+	table.Render()
+}
+
+func (t *TabWriter) RenderPipelines(topologies []herokuls.PipelineTopology) {
+	table := t.newTable([]string{"Pipeline", "Stage", "Apps", "Dynos $/month", "Last Promotion", "Error"})
+	for _, topology := range topologies {
+		lastPromotion := formatDate(topology.LastPromotion)
+		if topology.LastPromotion != nil {
+			lastPromotion += " " + topology.LastPromotionDescription
+		}
+		table.Append([]string{topology.Pipeline, "", "", "", lastPromotion, ""})
+		for _, stage := range topology.Stages {
+			table.Append([]string{"", stage.Stage, strings.Join(stage.Apps, ","), formatCost(stage.DynoCost), "", stage.Error})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderAddOns(inventory herokuls.AddOnInventory) {
+	teams := t.newTable([]string{"Team", "Addons", "Error"})
+	for _, team := range inventory.Teams {
+		teams.Append([]string{team.Team, strconv.Itoa(team.AddOns), team.Error})
+	}
+	teams.Render()
+
+	table := t.newTable([]string{"Service", "Addons", "$/month"})
+	for _, usage := range inventory.Services {
+		table.Append([]string{usage.Service, strconv.Itoa(usage.Total), formatCost(usage.MonthlyCost)})
+	}
+	table.Render()
+
+	addOns := t.newTable([]string{"Name", "Service", "Plan", "State", "Billing App", "Attached Apps", "Region", "Created", "$/month", "Flags"})
+	for _, addOn := range inventory.AddOns {
+		addOns.Append([]string{
+			addOn.Name,
+			addOn.Service,
+			addOn.Plan,
+			addOn.State,
+			addOn.BillingApp,
+			strings.Join(addOn.AttachedApps, ","),
+			addOn.Region,
+			formatDate(&addOn.CreatedAt),
+			formatCost(addOn.MonthlyCost),
+			strings.Join(addOn.Flags, ","),
+		})
+	}
+	addOns.Render()
+}
+
+func (t *TabWriter) RenderAllowlists(teamsAllowlist []herokuls.TeamAllowlist) {
+	table := t.newTable([]string{"Team", "Allowlist", "Allowed Not Desired", "Desired Not Allowed", "Flags"})
+	for _, teamAllowlist := range teamsAllowlist {
+		flags := strings.Join(teamAllowlist.Flags, ",")
+		if teamAllowlist.Error != "" {
+			flags += " (" + teamAllowlist.Error + ")"
+		}
+		table.Append([]string{
+			teamAllowlist.Team,
+			strings.Join(teamAllowlist.Allowlist, ","),
+			strings.Join(teamAllowlist.AllowedNotDesired, ","),
+			strings.Join(teamAllowlist.DesiredNotAllowed, ","),
+			flags,
+		})
+	}
+	table.Render()
+
+	addOns := t.newTable([]string{"Team", "Name", "Addon", "Service", "Reasons"})
+	addOns.SetCaption(true, "Non compliant add-ons")
+	for _, teamAllowlist := range teamsAllowlist {
+		for _, addOn := range teamAllowlist.NonCompliantAddOns {
+			addOns.Append([]string{teamAllowlist.Team, addOn.App, addOn.AddOn, addOn.Service, strings.Join(addOn.Reasons, ",")})
+		}
+	}
+	if addOns.NumLines() > 0 {
+		addOns.Render()
+	}
+}
+
+func (t *TabWriter) RenderPolicy(results []herokuls.PolicyResult) {
+	table := t.newTable([]string{"Rule", "Result", "Evaluated", "Name", "Organization", "Reasons"})
+	for _, result := range results {
+		status := "pass"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		var reason string
+		if result.Evaluated == 0 {
+			reason = herokuls.PolicyNoMatchingApps
+		}
+		table.Append([]string{result.Rule, status, strconv.Itoa(result.Evaluated), "", "", reason})
+		for _, violation := range result.Violations {
+			table.Append([]string{"", "", "", violation.App, violation.Organization, strings.Join(violation.Reasons, ", ")})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderDrains(appsDrains []herokuls.AppDrains) {
+	table := t.newTable([]string{"Name", "Environment", "Drain", "Source", "Flags"})
+	for _, appDrains := range appsDrains {
+		table.Append([]string{appDrains.App, appDrains.Environment, "", "", strings.Join(appDrains.Flags, ",")})
+		for _, drain := range appDrains.Drains {
+			table.Append([]string{"", "", drain.URL, drain.Source, strings.Join(drain.Flags, ",")})
+		}
+	}
+	table.Render()
+}
+
+func (t *TabWriter) RenderWebhooks(appsWebhooks []herokuls.AppWebhook) {
+	table := t.newTable([]string{"Name", "Host", "Include", "Level", "Last Delivery", "Status", "Failures", "Flags"})
+	var previousApp string
+	for _, webhook := range appsWebhooks {
+		name := webhook.App
+		if name == previousApp {
+			name = ""
 		}
+		previousApp = webhook.App
+		table.Append([]string{
+			name,
+			webhook.Host,
+			strings.Join(webhook.Include, ","),
+			webhook.Level,
+			formatDate(webhook.LastDelivery),
+			webhook.LastStatus,
+			strconv.Itoa(webhook.ConsecutiveFailures) + "/" + strconv.Itoa(webhook.Deliveries),
+			strings.Join(webhook.Flags, ","),
+		})
+	}
+	table.Render()
+}
 
+func (t *TabWriter) RenderFeatures(report herokuls.FeatureReport) {
+	table := t.newTable(append([]string{"Name", "Environment"}, report.Features...))
+	for _, app := range report.Apps {
+		table.Append(append([]string{app.App, app.Environment}, featureMatrixRow(report.Features, app.Enabled, "x")...))
 	}
 	table.Render()
+
+	teams := t.newTable([]string{"Team", "Enabled Features"})
+	teams.SetCaption(true, "Team features")
+	for _, team := range report.Teams {
+		teams.Append([]string{team.Team, strings.Join(team.Enabled, ",")})
+	}
+	teams.Render()
+}
+
+func (t *TabWriter) RenderBuilds(appsBuilds []herokuls.AppBuild) {
+	table := t.newTable([]string{"Name", "Stack", "Buildpacks", "Last Build", "Status", "Duration", "Slug MB", "Flags"})
+	for _, appBuild := range appsBuilds {
+		var duration string
+		if appBuild.LastBuildAt != nil {
+			duration = strconv.Itoa(appBuild.BuildSeconds) + "s"
+		}
+		table.Append([]string{
+			appBuild.App,
+			appBuild.Stack,
+			formatBuildpacks(appBuild.Buildpacks, ","),
+			formatDate(appBuild.LastBuildAt),
+			appBuild.LastBuildStatus,
+			duration,
+			formatMegabytes(appBuild.SlugSize),
+			strings.Join(appBuild.Flags, ","),
+		})
+	}
+	table.SetCaption(true, "Slug size limit is "+formatMegabytes(herokuls.SlugSizeLimit)+" MB.")
+	table.Render()
+}
+
+// newTable table with borders shared by every listing
+func (t *TabWriter) newTable(header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(t.fileOutput)
+	table.SetHeader(header)
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	return table
 }